@@ -0,0 +1,533 @@
+// Package commands has the command line definitions shared by the iww command and the ibmcloud plugin.
+// The binaries only differ in how the credentials and the region, resource group and account defaults are provided.
+package commands
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/powellquiring/iww/iww"
+	"github.com/urfave/cli/v2"
+)
+
+// Target is the account, region and resource group used when not provided by a flag or the profile,
+// the ibmcloud target for the plugin
+type Target struct {
+	AccountID         string
+	Region            string
+	ResourceGroupName string
+	ResourceGroupID   string
+}
+
+// Environment is provided by the binary
+type Environment struct {
+	Flags []cli.Flag // additional global flags, like --apikey
+	// Credentials used when the command line and the profile do not provide any, called after the flags are parsed
+	Credentials func(c *cli.Context) iww.Credentials
+	// NoCredentials is the error message when there are no credentials
+	NoCredentials string
+	Target        Target
+}
+
+// Command is the subset of a cli.Command needed to describe it elsewhere, like the plugin metadata
+type Command struct {
+	Name  string
+	Usage string
+	Flags []cli.Flag
+}
+
+// state shared by the flags and actions of one app
+type state struct {
+	environment *Environment
+	profile     *iww.Profile   // first selected profile, nil if none
+	profiles    []*iww.Profile // all selected profiles
+	config      *iww.Config
+	overrides   []func(p *iww.Profile) // command line changes to the profiles, see overrideProfiles
+}
+
+// NewApp returns the iww app
+func NewApp(environment *Environment) *cli.App {
+	s := &state{environment: environment}
+	return &cli.App{
+		Name:     "iww",
+		Usage:    "ibm cloud world wide operations on existing resources",
+		Flags:    append(append([]cli.Flag{}, environment.Flags...), globalFlags()...),
+		Before:   s.before,
+		Commands: s.commands(),
+	}
+}
+
+// Commands returns the commands without actions
+func Commands() []Command {
+	ret := make([]Command, 0)
+	for _, command := range (&state{environment: &Environment{}}).commands() {
+		ret = append(ret, Command{Name: command.Name, Usage: command.Usage, Flags: command.Flags})
+	}
+	return ret
+}
+
+func globalFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "profile",
+			Aliases: []string{"p"},
+			Usage:   "profile from the config file ~/.config/iww/config.yaml (or IWW_CONFIG), default_profile if not provided.  A comma separated list for multiple accounts",
+			EnvVars: []string{"IWW_PROFILE"},
+		},
+		&cli.StringFlag{
+			Name:    "trusted-profile-id",
+			Usage:   "assume this IAM trusted profile using a compute resource token instead of an apikey",
+			EnvVars: []string{"IWW_TRUSTED_PROFILE_ID"},
+		},
+		&cli.StringFlag{
+			Name:    "trusted-profile-name",
+			Usage:   "assume this IAM trusted profile, by name, using the compute resource token file",
+			EnvVars: []string{"IWW_TRUSTED_PROFILE_NAME"},
+		},
+		&cli.StringFlag{
+			Name:    "trusted-profile-crn",
+			Usage:   "assume this IAM trusted profile, by crn, using the VSI metadata service",
+			EnvVars: []string{"IWW_TRUSTED_PROFILE_CRN"},
+		},
+		&cli.StringFlag{
+			Name:    "cr-token-file",
+			Usage:   "compute resource token file, like a kubernetes projected service account token",
+			EnvVars: []string{"IWW_CR_TOKEN_FILE"},
+		},
+		&cli.BoolFlag{
+			Name:    "vpc-instance",
+			Usage:   "get the compute resource token from the VSI metadata service",
+			EnvVars: []string{"IWW_VPC_INSTANCE"},
+		},
+	}
+}
+
+// flags used by more than one command
+func accountFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "account",
+			Usage: "account id, repeat for multiple accounts.  Uses the profile in the config file with the account or assumes the assume_profile trusted profile in the account",
+		},
+		&cli.BoolFlag{
+			Name:  "enterprise",
+			Usage: "all of the accounts in the enterprise of the credentials account, credentials for each account like --account",
+		},
+	}
+}
+
+func verboseFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "verbose",
+		Usage:   "print progress and diagnostic messages while reading and removing resources",
+		Aliases: []string{"v"},
+	}
+}
+
+func scopeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "group",
+			Aliases: []string{"g"},
+			Usage:   "resource group for resources",
+		},
+		&cli.StringFlag{
+			Name:    "region",
+			Aliases: []string{"r"},
+			Usage:   "restrict resources to specific regions, us-south or us-south,eu-de ....",
+		},
+		&cli.BoolFlag{
+			Name:    "all-resource-groups",
+			Aliases: []string{"ag"},
+			Usage:   "all resource groups not just the one from the profile or ibmcloud target",
+		},
+		&cli.BoolFlag{
+			Name:    "all-regions",
+			Aliases: []string{"ar"},
+			Usage:   "all regions not just the ones from the profile or ibmcloud target",
+		},
+	}
+}
+
+func vpcidFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "vpcid",
+		Aliases: []string{"vpc"},
+		Usage:   "restrict resources to be from one vpc id",
+	}
+}
+
+func flags(flagLists ...[]cli.Flag) []cli.Flag {
+	ret := make([]cli.Flag, 0)
+	for _, flagList := range flagLists {
+		ret = append(ret, flagList...)
+	}
+	return ret
+}
+
+func (s *state) commands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "ls",
+			Usage: "list matching resources in the ibm cloud.  No options to list all.  Consider trying -s to save to temp file, edit temp file leaving what needs to be removed then iww rm -s to remove them",
+			Flags: flags(accountFlags(), scopeFlags(), []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "fast",
+					Usage: "fast as possible do not read resource specific attributes",
+				},
+				verboseFlag(),
+				&cli.BoolFlag{
+					Name:  "tree",
+					Usage: "nest the resources: account, resource group, region, vpc, subnet, instance, ... and resource instance, key, ...",
+				},
+				&cli.BoolFlag{
+					Name:    "save",
+					Usage:   "save in the file /tmp/ls.txt, or the save_file of the profile",
+					Aliases: []string{"s"},
+				},
+				vpcidFlag(),
+			}),
+			Action: s.requireCredentials(s.ls),
+		},
+		{
+			Name:  "rm",
+			Usage: "remove resources",
+			Flags: flags(accountFlags(), scopeFlags(), []cli.Flag{
+				verboseFlag(),
+				&cli.BoolFlag{
+					Name:    "force",
+					Usage:   "do not prompt with y/n just assume y and rm resources",
+					Aliases: []string{"f"},
+				},
+				&cli.BoolFlag{
+					Name:    "save",
+					Usage:   "only consider crns from a saved file, see ls --save",
+					Aliases: []string{"s"},
+				},
+				&cli.StringFlag{
+					Name:  "file",
+					Usage: "only consider crns from this file, first word in each line must be a crn",
+				},
+				&cli.StringFlag{
+					Name:    "crn",
+					Aliases: []string{"c"},
+					Usage:   "Delete on resource based on the crn",
+				},
+				&cli.BoolFlag{
+					Name:  "stop-instances",
+					Usage: "stop vpc instances before deleting them, or the stop_instances of the profile",
+				},
+				&cli.BoolFlag{
+					Name:  "empty-buckets",
+					Usage: "delete the objects, versions and delete markers in cos buckets before deleting them, or the empty_buckets of the profile",
+				},
+				vpcidFlag(),
+			}),
+			Action: s.requireCredentials(s.rm),
+		},
+		{
+			Name:  "graph",
+			Usage: "write the resources and the relations between them as a graph, select the resources like ls",
+			Flags: flags(scopeFlags(), []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "graph format: dot (graphviz) or mermaid",
+					Value: iww.GraphDot,
+				},
+				verboseFlag(),
+				vpcidFlag(),
+			}),
+			Action: s.requireCredentials(func(c *cli.Context) error {
+				accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+				return iww.Graph(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID, c.String("vpcid"), c.String("format"), c.Bool("verbose"))
+			}),
+		},
+		{
+			Name:  "image",
+			Usage: "vpc private image lifecycle",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "retire",
+					Usage: "deprecate the private images that ls would list instead of removing them, --obsolete to obsolete them",
+					Flags: flags(scopeFlags(), []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "obsolete",
+							Usage: "obsolete the images, obsolete images can not be used to provision instances",
+						},
+						&cli.BoolFlag{
+							Name:    "force",
+							Usage:   "do not prompt with y/n just assume y and retire the images",
+							Aliases: []string{"f"},
+						},
+						verboseFlag(),
+					}),
+					Action: s.requireCredentials(func(c *cli.Context) error {
+						accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+						return iww.ImageRetire(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID, c.Bool("obsolete"), c.Bool("force"), c.Bool("verbose"))
+					}),
+				},
+			},
+		},
+		{
+			Name:  "test",
+			Usage: "test existence of resources",
+			Flags: scopeFlags(),
+			Action: s.requireCredentials(func(c *cli.Context) error {
+				accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+				return iww.TstCommon(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID)
+			}),
+		},
+		{
+			// iww.TagCommon removes the resources instead of tagging them, refuse to run until tagging works
+			Name:   "tag",
+			Usage:  "tag matching resources - not working yet",
+			Hidden: true,
+			Action: func(c *cli.Context) error {
+				return errors.New("tag is not implemented")
+			},
+		},
+		{
+			Name:  "i",
+			Usage: "interactive terminal ui: browse the resources in a tree, mark the ones to remove and remove them",
+			Flags: flags(scopeFlags(), []cli.Flag{vpcidFlag()}),
+			Action: s.requireCredentials(func(c *cli.Context) error {
+				accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+				return iww.Interactive(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID, c.String("vpcid"))
+			}),
+		},
+	}
+}
+
+// before loads the profiles
+func (s *state) before(c *cli.Context) error {
+	config, err := iww.LoadConfig(iww.ConfigFileName())
+	if err != nil {
+		return err
+	}
+	s.config = config
+	for _, profileName := range strings.Split(c.String("profile"), ",") {
+		p, err := config.Profile(strings.TrimSpace(profileName))
+		if err != nil {
+			return err
+		}
+		if p != nil {
+			s.profiles = append(s.profiles, p)
+		}
+	}
+	if len(s.profiles) > 0 {
+		s.profile = s.profiles[0]
+	}
+	iww.UseProfile(s.profile)
+	return nil
+}
+
+// requireCredentials wraps an action, the credentials are not needed for help
+func (s *state) requireCredentials(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if s.credentials(c, s.profile) == (iww.Credentials{}) {
+			return errors.New(s.environment.NoCredentials)
+		}
+		return action(c)
+	}
+}
+
+// trustedProfile is the trusted profile from the command line, nil if not provided
+func trustedProfile(c *cli.Context) *iww.TrustedProfile {
+	ret := &iww.TrustedProfile{
+		ProfileID:   c.String("trusted-profile-id"),
+		ProfileName: c.String("trusted-profile-name"),
+		ProfileCRN:  c.String("trusted-profile-crn"),
+		CRTokenFile: c.String("cr-token-file"),
+		VpcInstance: c.Bool("vpc-instance"),
+	}
+	if *ret == (iww.TrustedProfile{}) {
+		return nil
+	}
+	return ret
+}
+
+// credentials for a profile: the trusted profile from the command line, then the profile, then the environment
+func (s *state) credentials(c *cli.Context, p *iww.Profile) iww.Credentials {
+	if tp := trustedProfile(c); tp != nil {
+		return tp.Credentials()
+	}
+	if p != nil && p.TrustedProfile != nil {
+		return p.TrustedProfile.Credentials()
+	}
+	if p != nil && p.ApikeyEnv != "" {
+		return iww.Credentials{Apikey: p.Apikey()}
+	}
+	if s.environment.Credentials == nil {
+		return iww.Credentials{}
+	}
+	return s.environment.Credentials(c)
+}
+
+// scope returns the account, region and resource group: the flags, then the profile, then the target
+func (s *state) scope(c *cli.Context) (accountID, region, resourceGroupName, resourceGroupID string) {
+	target := s.environment.Target
+	accountID = target.AccountID
+	if s.profile != nil && s.profile.Account != "" {
+		accountID = s.profile.Account
+	}
+	region, resourceGroupName, resourceGroupID = s.accountScope(c, s.profile, accountID)
+	return
+}
+
+// accountScope is the region and resource group in the account from the flags, the profile and the ibmcloud target.
+// The resource group of the ibmcloud target is only used in the account of the ibmcloud target
+func (s *state) accountScope(c *cli.Context, p *iww.Profile, accountID string) (region, resourceGroupName, resourceGroupID string) {
+	target := s.environment.Target
+	region = firstNonEmpty(c.String("region"), p.Region(), target.Region)
+	if c.Bool("all-regions") {
+		region = ""
+	}
+	if p != nil {
+		resourceGroupName = p.ResourceGroup
+	}
+	resourceGroupName = firstNonEmpty(c.String("group"), resourceGroupName)
+	if resourceGroupName == "" && accountID == target.AccountID {
+		resourceGroupName, resourceGroupID = target.ResourceGroupName, target.ResourceGroupID
+	}
+	if c.Bool("all-resource-groups") {
+		resourceGroupName, resourceGroupID = "", ""
+	}
+	return
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// accountTargets returns the accounts when more than one account is requested, otherwise nil
+func (s *state) accountTargets(c *cli.Context) ([]iww.AccountTarget, error) {
+	accounts := c.StringSlice("account")
+	if len(s.profiles) <= 1 && len(accounts) == 0 && !c.Bool("enterprise") {
+		return nil, nil
+	}
+	accountTarget := func(p *iww.Profile, accountID string) iww.AccountTarget {
+		region, resourceGroupName, resourceGroupID := s.accountScope(c, p, accountID)
+		return iww.AccountTarget{
+			Credentials:       s.credentials(c, p),
+			AccountID:         accountID,
+			Profile:           p,
+			Region:            region,
+			ResourceGroupName: resourceGroupName,
+			ResourceGroupID:   resourceGroupID,
+		}
+	}
+	baseAccountID, _, _, _ := s.scope(c)
+	// a profile for the account from the config file, otherwise assume_profile, see iww.AccountCredentials
+	otherAccountTarget := func(accountID string) iww.AccountTarget {
+		if p := s.accountProfile(accountID); p != nil {
+			return accountTarget(p, accountID)
+		}
+		target := accountTarget(s.profile, accountID)
+		if accountID != baseAccountID {
+			target.Credentials = iww.AccountCredentials(target.Credentials, s.profile, accountID)
+		}
+		return target
+	}
+	targets := make([]iww.AccountTarget, 0)
+	for _, p := range s.profiles {
+		targets = append(targets, accountTarget(p, p.Account))
+	}
+	for _, account := range accounts {
+		targets = append(targets, otherAccountTarget(account))
+	}
+	if c.Bool("enterprise") {
+		enterpriseTargets, err := iww.EnterpriseAccountTargets(accountTarget(s.profile, baseAccountID))
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range enterpriseTargets {
+			if p := s.accountProfile(target.AccountID); p != nil {
+				target = accountTarget(p, target.AccountID)
+			}
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// accountProfile is the profile for the account from the config file with the command line overrides, nil if none
+func (s *state) accountProfile(accountID string) *iww.Profile {
+	if s.config == nil {
+		return nil
+	}
+	p := s.config.AccountProfile(accountID)
+	if p == nil {
+		return nil
+	}
+	ret := *p
+	for _, override := range s.overrides {
+		override(&ret)
+	}
+	return &ret
+}
+
+func (s *state) ls(c *cli.Context) error {
+	if c.Bool("tree") {
+		s.overrideProfiles(func(p *iww.Profile) { p.Output = iww.OutputTree })
+	}
+	targets, err := s.accountTargets(c)
+	if err != nil {
+		return err
+	}
+	if targets != nil {
+		return iww.LsAccounts(targets, c.String("vpcid"), c.Bool("fast"), c.Bool("verbose"), c.Bool("save"))
+	}
+	accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+	return iww.LsCommon(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID, c.String("vpcid"), c.Bool("fast"), c.Bool("verbose"), c.Bool("save"))
+}
+
+func (s *state) rm(c *cli.Context) error {
+	save := c.Bool("save")
+	if fileName := c.String("file"); fileName != "" {
+		// the file replaces the save file
+		save = true
+		s.overrideProfiles(func(p *iww.Profile) { p.SaveFile = fileName })
+	}
+	if c.Bool("stop-instances") {
+		s.overrideProfiles(func(p *iww.Profile) { p.StopInstances = true })
+	}
+	if c.Bool("empty-buckets") {
+		s.overrideProfiles(func(p *iww.Profile) { p.EmptyBuckets = true })
+	}
+	targets, err := s.accountTargets(c)
+	if err != nil {
+		return err
+	}
+	if targets != nil {
+		return iww.RmAccounts(targets, c.String("vpcid"), c.String("crn"), c.Bool("force"), c.Bool("verbose"), save)
+	}
+	accountID, region, resourceGroupName, resourceGroupID := s.scope(c)
+	return iww.RmCommon(s.credentials(c, s.profile), accountID, region, resourceGroupName, resourceGroupID, c.String("vpcid"), c.String("crn"), c.Bool("force"), c.Bool("verbose"), save)
+}
+
+// overrideProfiles replaces the profiles with copies changed by override.  The profile is created if there is none
+func (s *state) overrideProfiles(override func(p *iww.Profile)) {
+	s.overrides = append(s.overrides, override)
+	overridden := func(p *iww.Profile) *iww.Profile {
+		ret := &iww.Profile{}
+		if p != nil {
+			*ret = *p
+		}
+		override(ret)
+		return ret
+	}
+	for i, p := range s.profiles {
+		s.profiles[i] = overridden(p)
+	}
+	if len(s.profiles) > 0 {
+		s.profile = s.profiles[0]
+	} else {
+		s.profile = overridden(s.profile)
+	}
+	iww.UseProfile(s.profile)
+}
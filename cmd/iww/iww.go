@@ -4,178 +4,27 @@ import (
 	"log"
 	"os"
 
+	"github.com/powellquiring/iww/cmd/internal/commands"
 	"github.com/powellquiring/iww/iww"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	var apikey string
-	var resourceGroup string
-	var fileName string
-	var crn string
-	var region string
-	var vpcid string
-	app := &cli.App{
-		Name:  "iww",
-		Usage: "ibm cloud world wide operations on existing resources",
+	app := commands.NewApp(&commands.Environment{
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "apikey",
 				Usage:       "apikey key to access resources, or use environment var",
-				Required:    true,
 				Destination: &apikey,
 				EnvVars:     []string{"APIKEY"},
 			},
 		},
-		Commands: []*cli.Command{
-			{
-				Name:  "ls",
-				Usage: "list matching resources in the ibm cloud.  API key required.  No options to list all.  Consider trying -s to save to temp file, edit temp file leaving what needs to be removed then iww rm -s to remove them",
-				Flags: []cli.Flag{
-					&cli.BoolFlag{
-						Name:  "fast",
-						Usage: "fast as possible do not read resource specific attributes",
-					},
-					&cli.BoolFlag{
-						Name:    "verbose",
-						Usage:   "fast as possible do not read resource specific attributes",
-						Aliases: []string{"v"},
-					},
-					&cli.BoolFlag{
-						Name:    "save",
-						Usage:   "save in the file /tmp/ls.txt",
-						Aliases: []string{"s"},
-					},
-					&cli.StringFlag{
-						Name:        "group",
-						Aliases:     []string{"g"},
-						Usage:       "resource group for resources",
-						Required:    false,
-						Destination: &resourceGroup,
-					},
-					&cli.StringFlag{
-						Name:        "region",
-						Aliases:     []string{"r"},
-						Usage:       "restrict resources to be from one specific region, us-south, ....",
-						Required:    false,
-						Destination: &region,
-					},
-					&cli.StringFlag{
-						Name:        "vpcid",
-						Aliases:     []string{"vpc"},
-						Usage:       "restrict resources to be from one vpc id",
-						Required:    false,
-						Destination: &vpcid,
-					},
-				},
-				Action: func(c *cli.Context) error {
-					return iww.Ls(apikey, region, resourceGroup, vpcid, c.Bool("fast"), c.Bool("verbose"), c.Bool("save"))
-				},
-			},
-			{
-				Name:  "rm",
-				Usage: "remove resources",
-				Flags: []cli.Flag{
-					&cli.BoolFlag{
-						Name:    "verbose",
-						Usage:   "fast as possible do not read resource specific attributes",
-						Aliases: []string{"v"},
-					},
-					&cli.BoolFlag{
-						Name:    "force",
-						Usage:   "do not prompt with y/n just assume y and rm resources",
-						Aliases: []string{"f"},
-					},
-					&cli.BoolFlag{
-						Name:    "save",
-						Usage:   "only consider crns from a saved file, see ls --save",
-						Aliases: []string{"s"},
-					},
-					&cli.StringFlag{
-						Name:        "group",
-						Aliases:     []string{"g"},
-						Usage:       "resource group for resources",
-						Required:    false,
-						Destination: &resourceGroup,
-					},
-					&cli.StringFlag{
-						Name:        "crn",
-						Aliases:     []string{"c"},
-						Usage:       "Delete on resource based on the crn",
-						Required:    false,
-						Destination: &crn,
-					},
-					&cli.StringFlag{
-						Name:        "region",
-						Aliases:     []string{"r"},
-						Usage:       "restrict resources to be from one specific region, us-south, ....",
-						Required:    false,
-						Destination: &region,
-					},
-					&cli.StringFlag{
-						Name:        "file",
-						Usage:       "take the list of resources to rm from a file, first word in each line must be a crn",
-						Required:    false,
-						Destination: &fileName,
-					},
-					&cli.StringFlag{
-						Name:        "vpcid",
-						Aliases:     []string{"vpc"},
-						Usage:       "restrict resources to be from one vpc id",
-						Required:    false,
-						Destination: &vpcid,
-					},
-				},
-				Action: func(c *cli.Context) error {
-					return iww.Rm(apikey, region, resourceGroup, fileName, vpcid, crn, c.Bool("force"), c.Bool("verbose"), c.Bool("save"))
-				},
-			},
-			{
-				Name:  "i",
-				Usage: "interactive - not working yet",
-				Action: func(c *cli.Context) error {
-					return iww.Interactive(apikey)
-				},
-			},
-			{
-				Name:  "test",
-				Usage: "test existence of resources",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:        "group",
-						Aliases:     []string{"g"},
-						Usage:       "resource group for resources",
-						Required:    false,
-						Destination: &resourceGroup,
-					},
-					&cli.StringFlag{
-						Name:        "region",
-						Aliases:     []string{"r"},
-						Usage:       "restrict resources to be from one specific region, us-south, ....",
-						Required:    false,
-						Destination: &region,
-					},
-					&cli.StringFlag{
-						Name:        "file",
-						Usage:       "take the list of resources to rm from a file, first word in each line must be a crn",
-						Required:    false,
-						Destination: &fileName,
-					},
-				},
-				Action: func(c *cli.Context) error {
-					return iww.Tst(apikey, region, resourceGroup)
-				},
-			},
-			{
-				Name:  "tag",
-				Usage: "tag matching resources - not working yet",
-				Action: func(c *cli.Context) error {
-					return iww.Tag(apikey, c.Args().First())
-				},
-			},
+		Credentials: func(c *cli.Context) iww.Credentials {
+			return iww.Credentials{Apikey: apikey}
 		},
-	}
-
+		NoCredentials: "apikey required: --apikey, APIKEY environment variable, apikey_env in the profile or a trusted profile",
+	})
 	err := app.Run(os.Args)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/configuration/core_config"
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// refresh the token when it is this close to expiring so it does not expire while a request is in flight
+const tokenRefreshWindow = 5 * time.Minute
+
+// pluginAuthenticator gets the IAM token from the ibmcloud cli and refreshes it through the plugin context
+// before it expires.  Long rm operations outlive a single token.
+type pluginAuthenticator struct {
+	mutex   sync.Mutex
+	context plugin.PluginContext
+	token   string
+	expiry  time.Time
+}
+
+func newPluginAuthenticator(context plugin.PluginContext) (*pluginAuthenticator, error) {
+	authenticator := &pluginAuthenticator{context: context}
+	if _, err := authenticator.activeToken(); err != nil {
+		return nil, err
+	}
+	return authenticator, nil
+}
+
+func (authenticator *pluginAuthenticator) AuthenticationType() string {
+	return core.AUTHTYPE_BEARER_TOKEN
+}
+
+func (authenticator *pluginAuthenticator) Validate() error {
+	return nil
+}
+
+func (authenticator *pluginAuthenticator) Authenticate(request *http.Request) error {
+	token, err := authenticator.activeToken()
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// activeToken returns the current token, refreshed if it is about to expire.  Safe for concurrent use,
+// the vpc regions are read in parallel
+func (authenticator *pluginAuthenticator) activeToken() (string, error) {
+	authenticator.mutex.Lock()
+	defer authenticator.mutex.Unlock()
+	if authenticator.token == "" {
+		authenticator.setToken(authenticator.context.IAMToken())
+	}
+	if authenticator.token == "" {
+		return "", errors.New("no-credentials, try: ibmcloud login")
+	}
+	if time.Now().Add(tokenRefreshWindow).After(authenticator.expiry) {
+		newToken, err := authenticator.context.RefreshIAMToken()
+		if err != nil {
+			if time.Now().Before(authenticator.expiry) {
+				// still usable, try again on the next request
+				return authenticator.token, nil
+			}
+			return "", errors.New("unable to refresh the IAM token, try: ibmcloud login, err: " + err.Error())
+		}
+		authenticator.setToken(newToken)
+	}
+	return authenticator.token, nil
+}
+
+func (authenticator *pluginAuthenticator) setToken(token string) {
+	authenticator.token = sanitizeToken(token)
+	authenticator.expiry = core_config.NewIAMTokenInfo(authenticator.token).Expiry
+}
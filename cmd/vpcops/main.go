@@ -5,30 +5,51 @@ import (
 	"text/template"
 )
 
+// subtypeToBasename is one vpc resource type, the sdk functions and types are named from Basename and Plural:
+// GetVPC, DeleteVPC, ListVpcs returning a VPCCollection with Vpcs []VPC
 type subtypeToBasename struct {
-	Subtype  string
-	Basename string
-	InVpc    bool
+	Subtype             string
+	Basename            string
+	Plural              string // List{{ .Plural }} and the collection field
+	InVpc               bool   // has a VPC reference
+	Status              string // Status, LifecycleState, ProvisioningStatus or "" if there is no status
+	Zone                bool   // has a Zone reference
+	ResourceGroupFilter bool   // List options can filter by resource group
+	Options             string // extra statement to adjust the list options
+	Collection          string // field in the collection returned by List, "" if the same as Plural
+	DeleteResult        bool   // Delete returns the resource as well as the response
+	Delete              string // Delete function when it is not Delete{{ .Basename }}
+	Intf                bool   // Get and List return the {{ .Basename }}Intf interface, the value is a *{{ .Basename }}
 }
 
 var VpcSubtypeOperationsMap = []subtypeToBasename{
-	{"vpc", "VPC", false},
-	{"subnet", "Subnet", true},
-	{"instance", "Instance", true},
-	{"volume", "Volume", false},
-	{"key", "Key", false},
-	{"load-balancer", "LoadBalancer", false},
-	{"floating-ip", "FloatingIP", false},
-	{"image", "Image", false},
-	{"public-gateway", "PublicGateway", true},
-	{"network-acl", "NetworkACL", true},
-	{"security-group", "SecurityGroup", true},
-	{"flow-log-collector", "FlowLogCollector", true},
-	{"instance-group", "InstanceGroup", true},
-	{"snapshot", "Snapshot", false},
-	{"image", "Image", false},
-	// {"vpn", "VPNGateway"},
-	// {"instance-template", "InstanceTemplate"},
+	{Subtype: "vpc", Basename: "VPC", Plural: "Vpcs", Status: "Status", ResourceGroupFilter: true},
+	{Subtype: "subnet", Basename: "Subnet", Plural: "Subnets", InVpc: true, Status: "Status", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "instance", Basename: "Instance", Plural: "Instances", InVpc: true, Status: "Status", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "volume", Basename: "Volume", Plural: "Volumes", Status: "Status", Zone: true},
+	{Subtype: "key", Basename: "Key", Plural: "Keys"},
+	{Subtype: "load-balancer", Basename: "LoadBalancer", Plural: "LoadBalancers", Status: "ProvisioningStatus"},
+	{Subtype: "floating-ip", Basename: "FloatingIP", Plural: "FloatingIps", Status: "Status", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "image", Basename: "Image", Plural: "Images", Status: "Status", ResourceGroupFilter: true, Options: `options.SetVisibility("private")`},
+	{Subtype: "public-gateway", Basename: "PublicGateway", Plural: "PublicGateways", InVpc: true, Status: "Status", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "network-acl", Basename: "NetworkACL", Plural: "NetworkAcls", InVpc: true, ResourceGroupFilter: true},
+	{Subtype: "security-group", Basename: "SecurityGroup", Plural: "SecurityGroups", InVpc: true, ResourceGroupFilter: true},
+	{Subtype: "flow-log-collector", Basename: "FlowLogCollector", Plural: "FlowLogCollectors", InVpc: true, Status: "LifecycleState", ResourceGroupFilter: true},
+	{Subtype: "instance-group", Basename: "InstanceGroup", Plural: "InstanceGroups", InVpc: true, Status: "Status"},
+	{Subtype: "snapshot", Basename: "Snapshot", Plural: "Snapshots", Status: "LifecycleState", ResourceGroupFilter: true},
+	{Subtype: "snapshot-consistency-group", Basename: "SnapshotConsistencyGroup", Plural: "SnapshotConsistencyGroups", Status: "LifecycleState", ResourceGroupFilter: true, DeleteResult: true},
+	{Subtype: "bare-metal-server", Basename: "BareMetalServer", Plural: "BareMetalServers", InVpc: true, Status: "Status", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "dedicated-host", Basename: "DedicatedHost", Plural: "DedicatedHosts", Status: "LifecycleState", Zone: true, ResourceGroupFilter: true},
+	{Subtype: "dedicated-host-group", Basename: "DedicatedHostGroup", Plural: "DedicatedHostGroups", Zone: true, ResourceGroupFilter: true, Collection: "Groups"},
+	{Subtype: "placement-group", Basename: "PlacementGroup", Plural: "PlacementGroups", Status: "LifecycleState"},
+	{Subtype: "endpoint-gateway", Basename: "EndpointGateway", Plural: "EndpointGateways", InVpc: true, Status: "LifecycleState", ResourceGroupFilter: true},
+	{Subtype: "share", Basename: "Share", Plural: "Shares", Status: "LifecycleState", Zone: true, ResourceGroupFilter: true, DeleteResult: true},
+	{Subtype: "backup-policy", Basename: "BackupPolicy", Plural: "BackupPolicies", Status: "LifecycleState", ResourceGroupFilter: true, DeleteResult: true, Intf: true},
+	{Subtype: "vpn-server", Basename: "VPNServer", Plural: "VPNServers", InVpc: true, Status: "LifecycleState", ResourceGroupFilter: true},
+	{Subtype: "virtual-network-interface", Basename: "VirtualNetworkInterface", Plural: "VirtualNetworkInterfaces", InVpc: true, Status: "LifecycleState", Zone: true, ResourceGroupFilter: true, DeleteResult: true, Delete: "DeleteVirtualNetworkInterfaces"},
+	{Subtype: "private-path-service-gateway", Basename: "PrivatePathServiceGateway", Plural: "PrivatePathServiceGateways", InVpc: true, Status: "LifecycleState", ResourceGroupFilter: true},
+	// {Subtype: "vpn", Basename: "VPNGateway"},
+	// {Subtype: "instance-template", Basename: "InstanceTemplate"},
 }
 
 var operation = `
@@ -49,16 +70,56 @@ type VpcSubtypeOperations interface {
 	Destroy(service *vpcv1.VpcV1, id string) ( /*response*/ interface{}, error)
 }
 
+// VpcResourceMetadata is extracted from a vpc resource returned by Get or List, strings are "" if not available
+type VpcResourceMetadata struct {
+	ID              string
+	Name            string
+	Vpcid           string
+	Status          string // status or lifecycle_state
+	Zone            string
+	CreatedAt       string
+	CRN             string
+	ResourceGroupID string
+	Resource        interface{} // *vpcv1.VPC, *vpcv1.Subnet, ...
+}
+
+// VpcSubtypeListOperations are implemented by the regular vpc resource types
+type VpcSubtypeListOperations interface {
+	// List all of the resources in the region, only the resource group if resourceGroupID is not ""
+	List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error)
+	// Metadata of the resource, false if the resource is not the expected type
+	Metadata(resource interface{}) (VpcResourceMetadata, bool)
+}
+
+func vpcString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 {{range . }}
 type VpcSpecific{{ .Basename }}Instance struct{}
 
 func (vpc *VpcSpecific{{ .Basename }}Instance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
-	return service.Delete{{ .Basename }}(service.NewDelete{{ .Basename }}Options(id))
+	{{- $delete := or .Delete (print "Delete" .Basename) }}
+	{{- if .DeleteResult }}
+	_, response, err := service.{{ $delete }}(service.New{{ $delete }}Options(id))
+	return response, err
+	{{- else }}
+	return service.{{ $delete }}(service.New{{ $delete }}Options(id))
+	{{- end }}
 }
 
 func (spec *VpcSpecific{{ .Basename }}Instance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	{{- if .Intf }}
+	resource, response, err := service.Get{{ .Basename }}(service.NewGet{{ .Basename }}Options(id))
+	instance, ok := resource.(*vpcv1.{{ .Basename }})
+	if err == nil && ok {
+	{{- else }}
 	instance, response, err := service.Get{{ .Basename }}(service.NewGet{{ .Basename }}Options(id))
 	if err == nil {
+	{{- end }}
 		return *instance.Name, {{if (.InVpc)}} *instance.VPC.ID {{else}} "" {{end}}, true, response, nil
 	} else {
 		if response != nil && response.StatusCode == 404 {
@@ -68,6 +129,72 @@ func (spec *VpcSpecific{{ .Basename }}Instance) Get(service *vpcv1.VpcV1, id str
 		}
 	}
 }
+
+func (spec *VpcSpecific{{ .Basename }}Instance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.{{ .Basename }})
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	{{- if .InVpc }}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	{{- end }}
+	{{- if .Status }}
+	ret.Status = vpcString(instance.{{ .Status }})
+	{{- end }}
+	{{- if .Zone }}
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	{{- end }}
+	return ret, true
+}
+
+func (spec *VpcSpecific{{ .Basename }}Instance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewList{{ .Plural }}Options()
+	{{- if .ResourceGroupFilter }}
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	{{- end }}
+	{{- if .Options }}
+	{{ .Options }}
+	{{- end }}
+	for {
+		collection, _, err := service.List{{ .Plural }}(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.{{ or .Collection .Plural }} {
+			metadata, _ := spec.Metadata({{ if not .Intf }}&{{ end }}collection.{{ or .Collection .Plural }}[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
 {{ end }}
 var VpcSubtypeOperationsMap = map[string]VpcSubtypeOperations{
 {{- range .}}
@@ -0,0 +1,240 @@
+package iww
+
+// multiple accounts, each account gets its own context and runs its own discovery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/platform-services-go-sdk/enterprisemanagementv1"
+)
+
+// AccountTarget is an account and the credentials used to discover the resources in the account
+type AccountTarget struct {
+	Credentials Credentials
+	AccountID   string   // looked up from the credentials if not provided
+	Profile     *Profile // may be nil
+
+	// the scope in the account, empty for all regions or all resource groups
+	Region            string
+	ResourceGroupName string
+	ResourceGroupID   string
+}
+
+// resetGlobalContext allows SetGlobalContext to create a new context for the next account
+func resetGlobalContext() {
+	GlobalContext = nil
+}
+
+// newAccountContext makes a new global context for the target
+func newAccountContext(target AccountTarget, vpcid string, verbose bool) (*Context, error) {
+	resetGlobalContext()
+	UseProfile(target.Profile)
+	if err := SetGlobalContextWithCredentials(target.Credentials, target.AccountID, target.Region, target.ResourceGroupName, target.ResourceGroupID, vpcid, verbose); err != nil {
+		resetGlobalContext()
+		return nil, err
+	}
+	if target.AccountID != "" {
+		if err := checkCredentialsAccount(GlobalContext.authenticator, target.AccountID); err != nil {
+			resetGlobalContext()
+			return nil, err
+		}
+	}
+	GlobalContext.showAccount = true
+	return GlobalContext, nil
+}
+
+// checkCredentialsAccount returns an error if the token of the authenticator is not for the account.  A token is
+// scoped to one account, the resources of another account are not visible with it
+func checkCredentialsAccount(authenticator core.Authenticator, accountID string) error {
+	token, err := bearerToken(authenticator)
+	if err != nil {
+		return err
+	}
+	tokenAccountID, err := accountFromToken(token)
+	if err != nil {
+		return errors.New("account " + accountID + ": can not verify the account of the credentials: " + err.Error())
+	}
+	if tokenAccountID != accountID {
+		return errors.New("account " + accountID + ": the credentials are for account " + tokenAccountID + ", add a profile with the account to the config file or set assume_profile, see AccountCredentials")
+	}
+	return nil
+}
+
+func (context *Context) getEnterpriseManagementClient() (*enterprisemanagementv1.EnterpriseManagementV1, error) {
+	return enterprisemanagementv1.NewEnterpriseManagementV1(&enterprisemanagementv1.EnterpriseManagementV1Options{
+		Authenticator: context.authenticator,
+		URL:           context.endpoint("enterprise", enterprisemanagementv1.DefaultServiceURL, ""),
+	})
+}
+
+// AccountCredentials returns the credentials for the account.  A token is only valid in one account: the api key of
+// the base credentials assumes the trusted profile named by assume_profile in the account, a trusted profile with the
+// same name can be created in each account of an enterprise with a trusted profile template.  Without assume_profile
+// the base credentials are returned, they only work in their own account, see checkCredentialsAccount
+func AccountCredentials(base Credentials, profile *Profile, accountID string) Credentials {
+	if profile == nil || profile.AssumeProfile == "" || base.Apikey == "" {
+		return base
+	}
+	return Credentials{Apikey: base.Apikey, AssumeProfileName: profile.AssumeProfile, AssumeAccountID: accountID}
+}
+
+// EnterpriseAccountTargets returns a target for each active account in the enterprise of the base account with the
+// credentials from AccountCredentials.  The region and resource group name of the base target are used for all of
+// the accounts, a resource group id is only valid in its own account
+func EnterpriseAccountTargets(base AccountTarget) ([]AccountTarget, error) {
+	context, err := newAccountContext(base, "", false)
+	if err != nil {
+		return nil, err
+	}
+	defer resetGlobalContext()
+	client, err := context.getEnterpriseManagementClient()
+	if err != nil {
+		return nil, err
+	}
+	enterprises, _, err := client.ListEnterprises(client.NewListEnterprisesOptions().SetAccountID(context.accountID))
+	if err != nil {
+		return nil, err
+	}
+	if len(enterprises.Resources) == 0 {
+		return nil, errors.New("account is not an enterprise account: " + context.accountID)
+	}
+	enterpriseID := *enterprises.Resources[0].ID
+
+	targets := make([]AccountTarget, 0)
+	options := client.NewListAccountsOptions().SetEnterpriseID(enterpriseID)
+	// limit the number of calls
+	for i := 0; i < 100; i++ {
+		accounts, _, err := client.ListAccounts(options)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts.Resources {
+			if account.State != nil && *account.State != "ACTIVE" {
+				continue
+			}
+			credentials := base.Credentials
+			if *account.ID != context.accountID {
+				credentials = AccountCredentials(base.Credentials, base.Profile, *account.ID)
+			}
+			targets = append(targets, AccountTarget{
+				Credentials:       credentials,
+				AccountID:         *account.ID,
+				Profile:           base.Profile,
+				Region:            base.Region,
+				ResourceGroupName: base.ResourceGroupName,
+			})
+		}
+		if accounts.NextURL == nil {
+			break
+		}
+		nextDocid, err := core.GetQueryParam(accounts.NextURL, "next_docid")
+		if err != nil {
+			return nil, err
+		}
+		options.SetNextDocid(*nextDocid)
+	}
+	return targets, nil
+}
+
+// forEachAccount makes the context of each target the global context and calls visit.  An account that fails is
+// logged and the others are still visited, the error names each of the failed accounts
+func forEachAccount(targets []AccountTarget, vpcid string, verbose bool, visit func(context *Context) error) error {
+	failed := make([]string, 0)
+	for _, target := range targets {
+		context, err := newAccountContext(target, vpcid, verbose)
+		if err == nil {
+			err = visit(context)
+		}
+		if err != nil {
+			accountID := target.AccountID
+			if accountID == "" && target.Profile != nil {
+				accountID = "profile:" + target.Profile.Name
+			}
+			log.Print("account ", accountID, " skipped, err:", err)
+			failed = append(failed, accountID)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New("accounts failed: " + strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// LsAccounts lists the resources in each of the accounts, each line of output starts with the account id
+func LsAccounts(targets []AccountTarget, vpcid string, fast bool, verbose, save bool) error {
+	if vpcid != "" && fast {
+		return errors.New("fast and vpcid are not compatible")
+	}
+	var f *os.File
+	return forEachAccount(targets, vpcid, verbose, func(context *Context) error {
+		wrappedResourceInstances, err := List(fast)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			f = os.Stdout
+			if save {
+				f = openSaveFile(context.saveFile)
+			}
+		}
+		if context.outputFormat == OutputText {
+			fmt.Fprintln(f, "## account", context.accountID)
+		}
+		return lsOutput(wrappedResourceInstances, f, fast)
+	})
+}
+
+// RmAccounts removes the resources in each of the accounts.  All accounts are listed then there is one
+// prompt before removing.  The accounts that can not be listed are skipped
+func RmAccounts(targets []AccountTarget, vpcid string, crn string, force bool, verbose, save bool) error {
+	contexts := make([]*Context, 0)
+	serviceInstancesByContext := make([][]*ResourceInstanceWrapper, 0)
+	listErr := forEachAccount(targets, vpcid, verbose, func(context *Context) error {
+		serviceInstances, err := List(false)
+		if err != nil {
+			return err
+		}
+		serviceInstances, err = parameterServiceInstances(serviceInstances, save, crn)
+		if err != nil {
+			return err
+		}
+		serviceInstances = pruneProtected(serviceInstances)
+		fmt.Println("## account", context.accountID)
+		lsOutput(serviceInstances, os.Stdout, false)
+		contexts = append(contexts, context)
+		serviceInstancesByContext = append(serviceInstancesByContext, serviceInstances)
+		return nil
+	})
+	if len(contexts) == 0 {
+		return listErr
+	}
+	if !force {
+		force = confirmRemove()
+	}
+	if !force {
+		return listErr
+	}
+	failed := make([]string, 0)
+	for i, context := range contexts {
+		// the operations use the global context
+		GlobalContext = context
+		fmt.Println("## account", context.accountID)
+		if err := RmServiceInstances(serviceInstancesByContext[i]); err != nil {
+			log.Print("account ", context.accountID, " err:", err)
+			failed = append(failed, context.accountID)
+		}
+	}
+	if len(failed) > 0 {
+		rmErr := errors.New("accounts not removed: " + strings.Join(failed, ","))
+		if listErr != nil {
+			return errors.New(listErr.Error() + ", " + rmErr.Error())
+		}
+		return rmErr
+	}
+	return listErr
+}
@@ -0,0 +1,69 @@
+package iww
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneByAccount(t *testing.T) {
+	assert := assert.New(t)
+	group := "g"
+	mine := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::vpc:r006-1"), &group, nil)
+	other := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/222::vpc:r006-2"), &group, nil)
+	fake := NewResourceInstanceWrapper(NewFakeCrn("is", "", "ikepolicy", "r006-3", "us-south"), &group, nil)
+	assert.Equal("111", mine.crn.account())
+	assert.Equal("ACCOUNT", fake.crn.account())
+	pruned := pruneWrappedResourceInstancesByAccount([]*ResourceInstanceWrapper{mine, other, fake}, "111")
+	assert.Equal([]*ResourceInstanceWrapper{mine, fake}, pruned)
+}
+
+func TestCheckCredentialsAccount(t *testing.T) {
+	assert := assert.New(t)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"account":{"bss":"111"}}`))
+	authenticator := &core.BearerTokenAuthenticator{BearerToken: "header." + payload + ".signature"}
+	assert.Nil(checkCredentialsAccount(authenticator, "111"))
+	assert.ErrorContains(checkCredentialsAccount(authenticator, "222"), "credentials are for account 111")
+	assert.Equal("", (&Crn{Crn: "crn:v1"}).account())
+}
+
+func testAccountToken(accountID string) string {
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(`{"account":{"bss":"`+accountID+`"}}`)) + ".signature"
+}
+
+func TestForEachAccount(t *testing.T) {
+	assert := assert.New(t)
+	saved := GlobalContext
+	defer func() { GlobalContext = saved }()
+	targets := []AccountTarget{
+		{Credentials: Credentials{Token: testAccountToken("111")}, AccountID: "111"},
+		// the credentials of another account are not used for 333
+		{Credentials: Credentials{Token: testAccountToken("111")}, AccountID: "333"},
+		{Credentials: Credentials{Token: testAccountToken("222")}, AccountID: "222"},
+	}
+	visited := make([]string, 0)
+	err := forEachAccount(targets, "", false, func(context *Context) error {
+		assert.True(context.showAccount)
+		visited = append(visited, context.accountID)
+		return nil
+	})
+	assert.Equal([]string{"111", "222"}, visited)
+	assert.EqualError(err, "accounts failed: 333")
+}
+
+func TestAccountCredentials(t *testing.T) {
+	assert := assert.New(t)
+	base := Credentials{Apikey: "key"}
+	assert.Equal(base, AccountCredentials(base, nil, "222"))
+	assert.Equal(base, AccountCredentials(base, &Profile{}, "222"))
+	credentials := AccountCredentials(base, &Profile{AssumeProfile: "iww-cleanup"}, "222")
+	assert.Equal(Credentials{Apikey: "key", AssumeProfileName: "iww-cleanup", AssumeAccountID: "222"}, credentials)
+	authenticator, err := credentials.newAuthenticator()
+	assert.NoError(err)
+	assert.IsType(&core.IamAssumeAuthenticator{}, authenticator)
+
+	token := Credentials{Token: testAccountToken("111")}
+	assert.Equal(token, AccountCredentials(token, &Profile{AssumeProfile: "iww-cleanup"}, "222"))
+}
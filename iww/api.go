@@ -14,6 +14,7 @@ TODO shore up the names: resource, ServiceInstance, etc are all kind of similar
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -40,7 +41,14 @@ type Key struct {
 }
 
 const Verbose = true
-const saveFile = "/tmp/ls.txt"
+const defaultSaveFile = "/tmp/ls.txt"
+
+// ls output formats
+const (
+	OutputText = "text"
+	OutputJson = "json"
+	OutputTree = "tree" // text nested by resource group, region and the relations between resources
+)
 
 func pbar(max int64, description ...string) *progressbar.ProgressBar {
 	if Verbose {
@@ -56,11 +64,17 @@ type ProgressBarWrapper struct {
 	total       int
 }
 
+const PBMAX = 1_000
+
 func NewProgressBarWrapper() *ProgressBarWrapper {
-	const PBMAX = 1_000
 	return &ProgressBarWrapper{progressBar: pbar(PBMAX), taken: 0, total: PBMAX}
 }
 
+// silent progress bar for when the terminal is used for something else, like the interactive ui
+func newSilentProgressBarWrapper() *ProgressBarWrapper {
+	return &ProgressBarWrapper{progressBar: progressbar.DefaultSilent(PBMAX), taken: 0, total: PBMAX}
+}
+
 // Make a new progress bar wrapper.  The size is a percent of total like progress
 func (pbw *ProgressBarWrapper) subProgress(percent float64) *ProgressBarWrapper {
 	pbmax := int((float64(pbw.total) * percent) + 0.5)
@@ -84,17 +98,24 @@ type Context struct {
 	verboseLogger      *log.Logger
 	progressBarWrapper *ProgressBarWrapper
 	authenticator      core.Authenticator
-	// apikey or token but not both, seems like authenticator would be enough but services like key protect
-	// do not use an authenticator
-	apikey            string
-	token             string
+	// authenticator is used by all clients, see Credentials
 	accountID         string
 	region            string
+	regions           []string // region split on commas, empty for all regions
 	resourceGroupName string
 	isType            bool   // only consider infrastructure services, vpc
 	vpcid             string // only consider is resources that match the vpcid (isType must be true)
 	resourceGroupID   string // initialized early can be trusted to be nil if no resource group provided
 	crn               string // todo testing
+	// profile settings, see UseProfile
+	outputFormat  string
+	saveFile      string
+	protected     []*compiledProtectedRule
+	endpoints     map[string]string
+	showAccount   bool // more than one account is being listed, see LsAccounts
+	stopInstances bool // stop vpc instances before they are deleted
+	emptyBuckets  bool // delete the objects in cos buckets so the buckets can be deleted
+	pruning       bool // the resources are listed again while removing, the waitFor of the new wrappers is not needed
 	// the rest are initialized as needed and cached
 	iamClient                  *iamidentityv1.IamIdentityV1
 	IDToResourceGroupName      map[string]string
@@ -108,13 +129,19 @@ var GlobalContext *Context
 
 // return the cached context or create it the first time called
 func SetGlobalContext(apikey string, token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, verbose bool) error {
+	return SetGlobalContextWithCredentials(Credentials{Apikey: apikey, Token: token}, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose)
+}
+
+// SetGlobalContextWithCredentials is SetGlobalContext for any kind of credentials
+func SetGlobalContextWithCredentials(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, verbose bool) error {
 	var err error
 	if GlobalContext != nil {
 		return nil
 	}
 	GlobalContext = &Context{}
-	if !((apikey != "" && token == "") || (apikey == "" && token != "")) {
-		return errors.New("one of apikey or token must be provided (not both)")
+	GlobalContext.authenticator, err = creds.newAuthenticator()
+	if err != nil {
+		return err
 	}
 
 	if verbose {
@@ -126,31 +153,35 @@ func SetGlobalContext(apikey string, token string, accountID string, region stri
 	GlobalContext.progressBarWrapper = NewProgressBarWrapper()
 	defer GlobalContext.progressBarWrapper.progress(0.10)
 	GlobalContext.region = region
-	GlobalContext.apikey = apikey
-	GlobalContext.token = token
-	if token != "" {
-		GlobalContext.authenticator, err = core.NewBearerTokenAuthenticator(token)
-		if err != nil {
-			return err
-		}
-	} else {
-		GlobalContext.authenticator = &core.IamAuthenticator{ApiKey: apikey}
+	GlobalContext.regions = splitRegions(region)
+	if err = GlobalContext.applyProfile(activeProfile); err != nil {
+		return err
 	}
 
+	GlobalContext.accountID = accountID
 	if accountID == "" {
-		if apikey != "" {
+		if creds.Apikey != "" {
 			iamClient, err := GlobalContext.getIamClient()
 			if err != nil {
 				return err
 			}
 			do := &iamidentityv1.GetAPIKeysDetailsOptions{
-				IamAPIKey: &apikey,
+				IamAPIKey: &creds.Apikey,
 			}
 			apiKeyDetails, _, err := iamClient.GetAPIKeysDetails(do)
 			if err != nil {
 				return err
 			}
 			GlobalContext.accountID = *apiKeyDetails.AccountID
+		} else {
+			// trusted profiles and tokens carry the account in the access token
+			token, err := bearerToken(GlobalContext.authenticator)
+			if err != nil {
+				return err
+			}
+			if GlobalContext.accountID, err = accountFromToken(token); err != nil {
+				GlobalContext.verboseLogger.Print("no account id in the token, err:", err)
+			}
 		}
 	}
 	GlobalContext.resourceGroupName = resourceGroupName
@@ -166,6 +197,59 @@ func SetGlobalContext(apikey string, token string, accountID string, region stri
 	return SetGlobalContextResourceGroupID()
 }
 
+// splitRegions turns "us-south,eu-de" into a list of regions
+func splitRegions(region string) []string {
+	regions := make([]string, 0)
+	for _, r := range strings.Split(region, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// inRegion is true if there is no region restriction or if the region is one of the requested regions
+func (context *Context) inRegion(region string) bool {
+	if len(context.regions) == 0 {
+		return true
+	}
+	for _, r := range context.regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfile copies the profile settings that are not passed as parameters into the context
+func (context *Context) applyProfile(profile *Profile) error {
+	context.outputFormat = OutputText
+	context.saveFile = defaultSaveFile
+	context.endpoints = map[string]string{}
+	if profile == nil {
+		return nil
+	}
+	if profile.Output != "" {
+		context.outputFormat = profile.Output
+	}
+	if profile.SaveFile != "" {
+		context.saveFile = profile.SaveFile
+	}
+	context.stopInstances = profile.StopInstances
+	context.emptyBuckets = profile.EmptyBuckets
+	for service, endpoint := range profile.Endpoints {
+		context.endpoints[service] = endpoint
+	}
+	for _, rule := range profile.Protected {
+		compiled, err := rule.compile()
+		if err != nil {
+			return err
+		}
+		context.protected = append(context.protected, compiled)
+	}
+	return nil
+}
+
 func SetGlobalContextResourceGroupID() error {
 	if GlobalContext.resourceGroupID != "" {
 		return nil // already have the ID
@@ -194,6 +278,7 @@ State transition
 start      -Fetch->   exists | deleted
 exists     -Fetch->   exists
 exists     -Fetch->   deleted
+exists     -Fetch->   destroying (vpc resource is pending, updating or deleting)
 exists     -Destroy-> destroying
 destroying -Fetch->   exists
 destroying -Fetch->   destroying
@@ -245,8 +330,8 @@ func NewCrn(crn string) *Crn {
 	parts := strings.Split(crn, ":")
 	region := parts[5]
 	zone := ""
-	zoneDash := region[len(region)-2 : len(region)-1]
-	if zoneDash == "-" {
+	// iam identity crns, like service ids, do not have a region
+	if len(region) >= 2 && region[len(region)-2:len(region)-1] == "-" {
 		zone = region[len(region)-1:]
 		if zone >= "0" && zone <= "9" {
 			region = region[:len(region)-2]
@@ -283,6 +368,15 @@ func (crn *Crn) AsString() string {
 	return crn.Crn
 }
 
+// account returns the account id in the crn, "ACCOUNT" for fake crns
+func (crn *Crn) account() string {
+	parts := strings.Split(crn.Crn, ":")
+	if len(parts) < 7 {
+		return ""
+	}
+	return strings.TrimPrefix(parts[6], "a/")
+}
+
 // wrappers are for both resourcecontrollerv2.ResourceInstance and ServiceInstance.
 // Also contain state
 type ResourceInstanceWrapper struct {
@@ -292,13 +386,43 @@ type ResourceInstanceWrapper struct {
 	//context         *Context
 	ResourceGroupID *string
 	Name            *string
-	resource        interface{}
+	resource        interface{} // resource read by the last Fetch, nil if not available
+	// parent is set by the finder: the instance of a sub instance or the source of a resource key
+	parent *ResourceInstanceWrapper
+	// owner is the kubernetes cluster that created the vpc resource, see ResourceFinderKubernetes
+	owner *ResourceInstanceWrapper
+	// waitFor are the resources that must be deleted first when they are removed together, see rmServiceInstances
+	waitFor []*ResourceInstanceWrapper
 }
 
 func (ri *ResourceInstanceWrapper) Fetch() { ri.operations.Fetch(ri) }
 func (ri *ResourceInstanceWrapper) FormatInstance(fast bool) string {
+	if ri.owner != nil {
+		return ri.operations.FormatInstance(ri, fast) + " cluster:" + ownerName(ri)
+	}
 	return ri.operations.FormatInstance(ri, fast)
 }
+
+// ownerName is the name of the owner of the resource, "" if there is no owner
+func ownerName(ri *ResourceInstanceWrapper) string {
+	if ri.owner == nil {
+		return ""
+	}
+	if ri.owner.Name != nil {
+		return *ri.owner.Name
+	}
+	return ri.owner.crn.id
+}
+
+// waiting returns a resource in waitFor that is being removed and still exists, nil if there is none
+func (ri *ResourceInstanceWrapper) waiting(removing map[*ResourceInstanceWrapper]bool) *ResourceInstanceWrapper {
+	for _, first := range ri.waitFor {
+		if removing[first] && first.state != SIStateDeleted {
+			return first
+		}
+	}
+	return nil
+}
 func (ri *ResourceInstanceWrapper) Destroy() { ri.operations.Destroy(ri) }
 
 // ResourceGroup returns a string representation of the resource group.  Name if available
@@ -346,6 +470,7 @@ func (s *TypicalServiceOperations) Fetch(si *ResourceInstanceWrapper) {
 		}
 	} else {
 		si.state = SIStateExists
+		si.resource = s.getResult
 		if s.getResult != nil && *s.getResult.State == "removed" {
 			si.state = SIStateDeleted
 		}
@@ -411,15 +536,29 @@ func pruneWrappedResourceInstancesByIs(wrappedResourceInstances []*ResourceInsta
 	return ret
 }
 
+// pruneWrappedResourceInstancesByAccount removes resources from other accounts, fake crns do not have an account
+func pruneWrappedResourceInstancesByAccount(wrappedResourceInstances []*ResourceInstanceWrapper, accountID string) []*ResourceInstanceWrapper {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range wrappedResourceInstances {
+		account := ri.crn.account()
+		if account == accountID || account == "ACCOUNT" {
+			ret = append(ret, ri)
+		}
+	}
+	return ret
+}
+
 func (context *Context) getIamClient() (client *iamidentityv1.IamIdentityV1, err error) {
 	return iamidentityv1.NewIamIdentityV1UsingExternalConfig(&iamidentityv1.IamIdentityV1Options{
 		Authenticator: context.authenticator,
+		URL:           context.endpoint("iam", iamidentityv1.DefaultServiceURL, ""),
 	})
 }
 
 func (context *Context) getResourceManagerClient() (resourceManagerClient *resourcemanagerv2.ResourceManagerV2, err error) {
 	return resourcemanagerv2.NewResourceManagerV2(&resourcemanagerv2.ResourceManagerV2Options{
 		Authenticator: context.authenticator,
+		URL:           context.endpoint("resource_manager", resourcemanagerv2.DefaultServiceURL, ""),
 	})
 }
 
@@ -427,10 +566,18 @@ func ApiEndpoint(documentedApiEndpoint string, region string) string {
 	return strings.Replace(documentedApiEndpoint, "<region>", region, 1)
 }
 
+// endpoint is the ApiEndpoint unless the profile overrides the endpoint for the service
+func (context *Context) endpoint(service string, documentedApiEndpoint string, region string) string {
+	if override, ok := context.endpoints[service]; ok {
+		return ApiEndpoint(override, region)
+	}
+	return ApiEndpoint(documentedApiEndpoint, region)
+}
+
 func (context *Context) getVpcClientFromRegion(region string) (service *vpcv1.VpcV1, err error) {
 	return vpcv1.NewVpcV1(&vpcv1.VpcV1Options{
 		Authenticator: MustGlobalContext().authenticator,
-		URL:           ApiEndpoint("https://<region>.iaas.cloud.ibm.com/v1", region),
+		URL:           context.endpoint("vpc", "https://<region>.iaas.cloud.ibm.com/v1", region),
 	})
 }
 
@@ -444,7 +591,7 @@ func (context *Context) getTransitGatewayClient(crn *Crn) (*transitgatewayapisv1
 	options := &transitgatewayapisv1.TransitGatewayApisV1Options{
 		Version:       &version,
 		Authenticator: context.authenticator,
-		URL:           "https://transit.cloud.ibm.com/v1",
+		URL:           context.endpoint("transit", "https://transit.cloud.ibm.com/v1", ""),
 	}
 	return transitgatewayapisv1.NewTransitGatewayApisV1(options)
 	// todo
@@ -557,9 +704,13 @@ var resourceFinders []ResourceFinder = []ResourceFinder{
 	ResourceFinderSchematics{},
 	ResourceFinderTransitGateway{},
 	ResourceFinderVpc{},
+	ResourceFinderKubernetes{},
 	ResourceFinderResourceKeys{},
 	ResourceFinderDns{},
 	ResourceFinderKeyProtect{},
+	ResourceFinderCos{},
+	ResourceFinderSecretsManager{},
+	ResourceFinderIam{},
 }
 
 // Return the resources in the cloud, if no filters then all of them, see filtering
@@ -579,6 +730,9 @@ func ListExpandFastPruneAddOperations() ([]*ResourceInstanceWrapper, error) {
 	if context.isType {
 		wrappedResourceInstances = pruneWrappedResourceInstancesByIs(wrappedResourceInstances)
 	}
+	if context.showAccount {
+		wrappedResourceInstances = pruneWrappedResourceInstancesByAccount(wrappedResourceInstances, context.accountID)
+	}
 
 	// the original resource controller list only included resources from the resource group
 	// but the finders could have added resources in the wrong group.
@@ -663,27 +817,28 @@ func NewSubInstance(parent *ResourceInstanceWrapper, subType, id string, name *s
 	ret := NewResourceInstanceWrapper(crn, parent.ResourceGroupID, name)
 	// zone.resource = dz
 	ret.operations = operations
+	ret.parent = parent
 	return ret
 }
 
 // ls with apikey from iww command line
 func Ls(apikey, region string, resourceGroupName string, vpcid string, fast bool, verbose, save bool) error {
-	return LsCommon(apikey, "", "", region, resourceGroupName, "", vpcid, fast, verbose, save)
+	return LsCommon(Credentials{Apikey: apikey}, "", region, resourceGroupName, "", vpcid, fast, verbose, save)
 }
 
 // ls with context manager from ibmcloud cli
 func LsWithToken(token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, fast bool, verbose bool) error {
-	return LsCommon("", token, accountID, region, resourceGroupName, resourceGroupID, vpcid, fast, verbose, false)
+	return LsCommon(Credentials{Token: token}, accountID, region, resourceGroupName, resourceGroupID, vpcid, fast, verbose, false)
 }
 
-func LsCommon(apikey string, token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, fast bool, verbose, save bool) error {
+func LsCommon(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, fast bool, verbose, save bool) error {
 	if vpcid != "" {
 		if fast {
 			return errors.New("fast and vpcid are not compatible")
 		}
 
 	}
-	if err := SetGlobalContext(apikey, token, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil {
 		return err
 	}
 	wrappedResourceInstances, err := List(fast)
@@ -691,25 +846,29 @@ func LsCommon(apikey string, token string, accountID string, region string, reso
 		return err
 	}
 	f := os.Stdout
-
 	if save {
-		var err error
-		f, err = os.OpenFile(saveFile, os.O_WRONLY|os.O_CREATE, 0666)
-		if err != nil {
-			log.Fatal("create file failed:", saveFile, err)
-		}
-		err = f.Truncate(0)
-		if err != nil {
-			log.Fatal("truncate file failed:", saveFile, err)
-		}
-		_, err = f.Seek(0, 0)
-		if err != nil {
-			log.Fatal("seek file failed:", saveFile, err)
-		}
+		f = openSaveFile(MustGlobalContext().saveFile)
 	}
 	return lsOutput(wrappedResourceInstances, f, fast)
 }
 
+// openSaveFile opens and truncates the file used by ls --save
+func openSaveFile(saveFile string) *os.File {
+	f, err := os.OpenFile(saveFile, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		log.Fatal("create file failed:", saveFile, err)
+	}
+	err = f.Truncate(0)
+	if err != nil {
+		log.Fatal("truncate file failed:", saveFile, err)
+	}
+	_, err = f.Seek(0, 0)
+	if err != nil {
+		log.Fatal("seek file failed:", saveFile, err)
+	}
+	return f
+}
+
 func lsOutput(wrappedResourceInstances []*ResourceInstanceWrapper, f *os.File, fast bool) error {
 	context := MustGlobalContext()
 	unimplementedResourceInstances := make([]*ResourceInstanceWrapper, 0)
@@ -731,16 +890,27 @@ func lsOutput(wrappedResourceInstances []*ResourceInstanceWrapper, f *os.File, f
 			}
 		}
 	}
+	if context.outputFormat == OutputJson {
+		return printJsonResourceInstances(context, f, fast, map[string][]*ResourceInstanceWrapper{
+			"unimplemented": unimplementedResourceInstances,
+			"missing":       missingResourceInstances,
+			"exists":        existingResourceInstances,
+		})
+	}
+	printResourceInstances := PrintResourceInstances
+	if context.outputFormat == OutputTree {
+		printResourceInstances = PrintResourceTree
+	}
 	if len(unimplementedResourceInstances) > 0 {
 		fmt.Fprintln(f, "#Unimplemented resource instances")
-		PrintResourceInstances(context, f, fast, unimplementedResourceInstances)
+		printResourceInstances(context, f, fast, unimplementedResourceInstances)
 	}
 	if len(missingResourceInstances) > 0 {
 		fmt.Fprintln(f, "#Missing resource instances")
-		PrintResourceInstances(context, f, fast, missingResourceInstances)
+		printResourceInstances(context, f, fast, missingResourceInstances)
 	}
 	fmt.Fprintln(f, "#Resource instances")
-	PrintResourceInstances(context, f, fast, existingResourceInstances)
+	printResourceInstances(context, f, fast, existingResourceInstances)
 	return nil
 }
 
@@ -750,6 +920,14 @@ func (ris RIWs) Len() int           { return len(ris) }
 func (ris RIWs) Swap(i, j int)      { ris[i], ris[j] = ris[j], ris[i] }
 func (ris RIWs) Less(i, j int) bool { return ris[i].crn.Crn < ris[j].crn.Crn }
 
+// formatInstance is the formatted resource, it starts with the account id when more than one account is listed
+func (context *Context) formatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	if context.showAccount {
+		return context.accountID + " " + ri.FormatInstance(fast)
+	}
+	return ri.FormatInstance(fast)
+}
+
 func PrintResourceInstances(context *Context, f *os.File, fast bool, wrappedResourceInstances []*ResourceInstanceWrapper) {
 	// Sort the instance by resource group
 	// byResourceGroup := make(map[string][]*ResourceInstanceWrapper)
@@ -772,9 +950,171 @@ func PrintResourceInstances(context *Context, f *os.File, fast bool, wrappedReso
 
 		fmt.Fprintln(f, "#", groupId, "(", context.getResourceGroupName(groupId, fast), ")")
 		for _, ri := range ris {
-			fmt.Fprintln(f, ri.FormatInstance(fast))
+			fmt.Fprintln(f, context.formatInstance(ri, fast))
+		}
+	}
+}
+
+// PrintResourceTree prints the resources nested: account, resource group, region and then the resources nested by
+// their relations, like vpc, subnet, instance
+func PrintResourceTree(context *Context, f *os.File, fast bool, wrappedResourceInstances []*ResourceInstanceWrapper) {
+	parents := relationParents(resourceRelations(wrappedResourceInstances))
+	children := relationChildren(parents)
+	rootsByGroupRegion := make(map[string]map[string]RIWs)
+	for _, ri := range wrappedResourceInstances {
+		if _, ok := parents[ri]; ok {
+			continue
+		}
+		groupID := *ri.ResourceGroupID
+		if rootsByGroupRegion[groupID] == nil {
+			rootsByGroupRegion[groupID] = make(map[string]RIWs)
+		}
+		rootsByGroupRegion[groupID][ri.crn.region] = append(rootsByGroupRegion[groupID][ri.crn.region], ri)
+	}
+	var printResource func(ri *ResourceInstanceWrapper, indent string)
+	printResource = func(ri *ResourceInstanceWrapper, indent string) {
+		fmt.Fprintln(f, indent+ri.FormatInstance(fast))
+		for _, child := range children[ri] {
+			printResource(child, indent+"  ")
+		}
+	}
+	fmt.Fprintln(f, "# account", context.accountID)
+	groupIDs := make([]string, 0)
+	for groupID := range rootsByGroupRegion {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+	for _, groupID := range groupIDs {
+		fmt.Fprintln(f, "  #", groupID, "(", context.getResourceGroupName(groupID, fast), ")")
+		rootsByRegion := rootsByGroupRegion[groupID]
+		regions := make([]string, 0)
+		for region := range rootsByRegion {
+			regions = append(regions, region)
+		}
+		sort.Strings(regions)
+		for _, region := range regions {
+			fmt.Fprintln(f, "    # region", region)
+			roots := rootsByRegion[region]
+			sort.Sort(roots)
+			for _, ri := range roots {
+				printResource(ri, "      ")
+			}
+		}
+	}
+}
+
+type jsonResourceInstance struct {
+	Account       string   `json:"account,omitempty"`
+	Crn           string   `json:"crn"`
+	ResourceType  string   `json:"resource_type"`
+	SubType       string   `json:"sub_type,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Region        string   `json:"region"`
+	ResourceGroup string   `json:"resource_group"`
+	State         string   `json:"state"`
+	Parent        string   `json:"parent,omitempty"` // crn of the parent, see PrintResourceTree
+	Status        string   `json:"status,omitempty"` // vpc status or lifecycle_state
+	Zone          string   `json:"zone,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	Backup        string   `json:"backup_policy_plan,omitempty"`  // snapshot created by the backup policy plan
+	AutoDelete    []string `json:"auto_delete_volumes,omitempty"` // volumes deleted with the instance
+	UsedBy        []string `json:"used_by,omitempty"`             // instances, templates and snapshots using the image
+	Cluster       string   `json:"cluster,omitempty"`             // kubernetes cluster that created the vpc resource
+	Expired       bool     `json:"expired,omitempty"`             // secrets manager secret past its expiration date
+}
+
+// printJsonResourceInstances writes one json object per line sorted by crn, state is the key of the map
+func printJsonResourceInstances(context *Context, f *os.File, fast bool, byState map[string][]*ResourceInstanceWrapper) error {
+	all := make([]jsonResourceInstance, 0)
+	for state, ris := range byState {
+		parents := relationParents(resourceRelations(ris))
+		for _, ri := range ris {
+			parent := ""
+			if parentRi, ok := parents[ri]; ok {
+				parent = parentRi.crn.Crn
+			}
+			name := ""
+			if ri.Name != nil {
+				name = *ri.Name
+			}
+			account := ""
+			if context.showAccount {
+				account = context.accountID
+			}
+			metadata, _ := vpcMetadata(ri)
+			backup := ""
+			if plan := snapshotBackupPolicyPlan(ri); plan != nil {
+				backup = vpcString(plan.ID)
+			}
+			all = append(all, jsonResourceInstance{
+				Account:       account,
+				Crn:           ri.crn.Crn,
+				ResourceType:  ri.crn.resourceType,
+				SubType:       ri.crn.vpcType,
+				Name:          name,
+				Region:        ri.crn.region,
+				ResourceGroup: context.getResourceGroupName(*ri.ResourceGroupID, fast),
+				State:         state,
+				Parent:        parent,
+				Status:        metadata.Status,
+				Zone:          metadata.Zone,
+				CreatedAt:     metadata.CreatedAt,
+				Backup:        backup,
+				AutoDelete:    instanceAutoDeleteVolumes(ri),
+				UsedBy:        vpcImageUsers(ri),
+				Cluster:       ownerName(ri),
+				Expired:       secretExpiredOf(ri),
+			})
 		}
 	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Crn < all[j].Crn })
+	encoder := json.NewEncoder(f)
+	for _, jri := range all {
+		if err := encoder.Encode(jri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isProtected is true if a protected rule from the profile matches the resource or the resource is list only
+func (context *Context) isProtected(ri *ResourceInstanceWrapper) bool {
+	if operations, ok := ri.operations.(listOnlyOperations); ok && operations.listOnly() {
+		return true
+	}
+	name := ""
+	if ri.Name != nil {
+		name = *ri.Name
+	}
+	resourceGroup := ""
+	if ri.ResourceGroupID != nil {
+		resourceGroup = context.getResourceGroupName(*ri.ResourceGroupID, false)
+	}
+	for _, rule := range context.protected {
+		if rule.matches(ri.crn.Crn, name, resourceGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+// listOnlyOperations are implemented by resources that may be listed but are never removed, they are protected
+type listOnlyOperations interface {
+	listOnly() bool
+}
+
+// pruneProtected removes the protected resources so they are never destroyed
+func pruneProtected(serviceInstances []*ResourceInstanceWrapper) []*ResourceInstanceWrapper {
+	context := MustGlobalContext()
+	ret := make([]*ResourceInstanceWrapper, 0)
+	for _, si := range serviceInstances {
+		if context.isProtected(si) {
+			fmt.Println("protected, will not remove:", context.formatInstance(si, true))
+		} else {
+			ret = append(ret, si)
+		}
+	}
+	return ret
 }
 
 /*
@@ -783,27 +1123,63 @@ start      -fetch->   exists | deleted
 exists     -fetch->   exists
 exists     -fetch->   deleted
 exists     -destroy-> destroying
+exists     -wait->    exists (a resource in waitFor is removed first, like the kubernetes cluster of a vpc)
 destroying -fetch->   exists
 destroying -fetch->   destroying
 destroying -fetch->   deleted
 */
 func RmServiceInstances(serviceInstances []*ResourceInstanceWrapper) error {
+	return rmServiceInstances(serviceInstances, printRmStatus)
+}
+
+// status of a resource reported while removing, see rmServiceInstances
+const (
+	RmStatusStart      = "start"
+	RmStatusDestroying = "destroying"
+	RmStatusWaiting    = "waiting"
+	RmStatusDeleted    = "deleted"
+)
+
+func printRmStatus(status string, si *ResourceInstanceWrapper) {
+	context := MustGlobalContext()
+	switch status {
+	case RmStatusStart, RmStatusDeleted:
+		fmt.Println(status+":", context.formatInstance(si, true))
+	default:
+		fmt.Println(status, context.formatInstance(si, true))
+	}
+}
+
+// rmPollInterval is the time between the passes over the resources being removed
+var rmPollInterval = 2 * time.Second
+
+// rmServiceInstances destroys the resources calling report as each resource changes state
+func rmServiceInstances(serviceInstances []*ResourceInstanceWrapper, report func(status string, si *ResourceInstanceWrapper)) error {
+	removing := make(map[*ResourceInstanceWrapper]bool)
+	for _, si := range serviceInstances {
+		removing[si] = true
+	}
 	nextServiceInstances := make([]*ResourceInstanceWrapper, 0)
 	for i := 0; i < 100 && len(serviceInstances) > 0; i++ {
 		for _, si := range serviceInstances {
 			switch si.state {
 			case SIStateStart:
-				fmt.Println("start:", si.FormatInstance(true))
+				report(RmStatusStart, si)
 				nextServiceInstances = append(nextServiceInstances, si)
 			case SIStateExists:
-				fmt.Println("destroying", si.FormatInstance(true))
-				si.Destroy()
+				if first := si.waiting(removing); first != nil {
+					MustGlobalContext().verboseLogger.Print("waiting for:", first.crn.Crn, " ", si.crn.Crn)
+					report(RmStatusWaiting, si)
+				} else {
+					report(RmStatusDestroying, si)
+					si.Destroy()
+				}
 				nextServiceInstances = append(nextServiceInstances, si)
 			case SIStateDestroying:
-				fmt.Println("waiting", si.FormatInstance(true))
+				report(RmStatusWaiting, si)
 				nextServiceInstances = append(nextServiceInstances, si)
 			case SIStateDeleted:
-				fmt.Println("deleted:", si.FormatInstance(true))
+				report(RmStatusDeleted, si)
 				// making some progress
 				i = 0
 				//nextServiceInstances = append(nextServiceInstances, si)
@@ -812,9 +1188,9 @@ func RmServiceInstances(serviceInstances []*ResourceInstanceWrapper) error {
 		}
 		serviceInstances = pruneResourcesThatDoNotExist(nextServiceInstances)
 		nextServiceInstances = make([]*ResourceInstanceWrapper, 0)
-		time.Sleep(2 * time.Second)
+		time.Sleep(rmPollInterval)
 	}
-	if len(nextServiceInstances) != 0 {
+	if len(serviceInstances) != 0 {
 		return errors.New("some service instances not deleted")
 	}
 	return nil
@@ -822,7 +1198,10 @@ func RmServiceInstances(serviceInstances []*ResourceInstanceWrapper) error {
 
 // prune out the resources that are no longer in the resource controller
 func pruneResourcesThatDoNotExist(nextServiceInstances []*ResourceInstanceWrapper) []*ResourceInstanceWrapper {
+	context := MustGlobalContext()
+	context.pruning = true
 	resources, err := ListExpandFastPruneAddOperations() // assume if they are not in the RC they can be pruned
+	context.pruning = false
 	if err != nil {
 		log.Print("can not prune resources, err:", err)
 		return nextServiceInstances
@@ -838,11 +1217,13 @@ func pruneResourcesThatDoNotExist(nextServiceInstances []*ResourceInstanceWrappe
 		}
 	}
 
-	// only return resources that are in the map
+	// only return resources that are in the map, the others are deleted so the resources waiting for them can continue
 	ret := make([]*ResourceInstanceWrapper, 0)
 	for _, ri := range nextServiceInstances {
 		if _, ok := crnToResource[ri.crn.Crn]; ok {
 			ret = append(ret, ri)
+		} else {
+			ri.state = SIStateDeleted
 		}
 	}
 	return ret
@@ -853,11 +1234,11 @@ func Rm(apikey, region string, resourceGroupName string, fileName string, vpcid
 		log.Print("rm from file not supported, fileName:", fileName)
 		return nil
 	}
-	return RmCommon(apikey, "", "", region, resourceGroupName, "", vpcid, crn, force, verbose, save)
+	return RmCommon(Credentials{Apikey: apikey}, "", region, resourceGroupName, "", vpcid, crn, force, verbose, save)
 }
 
 func RmWithToken(token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, crn string, force bool, verbose bool) error {
-	return RmCommon("", token, accountID, region, resourceGroupName, resourceGroupID, vpcid, crn, force, verbose, false)
+	return RmCommon(Credentials{Token: token}, accountID, region, resourceGroupName, resourceGroupID, vpcid, crn, force, verbose, false)
 }
 
 func crnsFromFile(fileName string) ([]string, error) {
@@ -899,13 +1280,18 @@ func parameterServiceInstances(serviceInstances []*ResourceInstanceWrapper, save
 			crns = []string{crn}
 		} else {
 			var err2 error
+			saveFile := MustGlobalContext().saveFile
 			crns, err2 = crnsFromFile(saveFile)
 			if err2 != nil {
 				log.Fatal("crns from file failed failed:", saveFile, err2)
 				return nil, err2
 			}
 		}
+		context := MustGlobalContext()
 		for _, crn := range crns {
+			if context.showAccount && !strings.Contains(crn, ":a/"+context.accountID+":") {
+				continue // another account, see RmAccounts
+			}
 			crnSi = nil
 			for _, si := range serviceInstances {
 				if si.crn.Crn == crn {
@@ -924,8 +1310,8 @@ func parameterServiceInstances(serviceInstances []*ResourceInstanceWrapper, save
 	return serviceInstances, nil
 }
 
-func RmCommon(apikey string, token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, crn string, force bool, verbose, save bool) error {
-	if err := SetGlobalContext(apikey, token, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil { // todo
+func RmCommon(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, crn string, force bool, verbose, save bool) error {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil { // todo
 		return err
 	}
 
@@ -936,31 +1322,41 @@ func RmCommon(apikey string, token string, accountID string, region string, reso
 
 	// filter the list of service instanes to intersect with the ones passed by params
 	serviceInstances, err = parameterServiceInstances(serviceInstances, save, crn)
+	serviceInstances = pruneProtected(serviceInstances)
 
 	lsOutput(serviceInstances, os.Stdout, false)
 	if !force {
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Remove these resources? Y/n: ")
-		text, _ := reader.ReadString('\n')
-		text = strings.ToLower(strings.TrimSpace(text))
-		fmt.Println(text)
-		force = len(text) == 0 || strings.HasPrefix(text, "y")
+		force = confirmRemove()
 	}
 
 	if !force {
 		return nil
 	}
 
-	RmServiceInstances(serviceInstances)
-	return nil
+	return RmServiceInstances(serviceInstances)
+}
+
+// confirmRemove prompts the user, an empty answer is yes
+func confirmRemove() bool {
+	return confirm("Remove these resources?")
+}
+
+// confirm asks the yes/no question, the default is yes
+func confirm(question string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print(question + " Y/n: ")
+	text, _ := reader.ReadString('\n')
+	text = strings.ToLower(strings.TrimSpace(text))
+	fmt.Println(text)
+	return len(text) == 0 || strings.HasPrefix(text, "y")
 }
 
 func Tst(apikey, region string, resourceGroupName string) error {
-	return TstCommon(apikey, "", "", region, resourceGroupName, "")
+	return TstCommon(Credentials{Apikey: apikey}, "", region, resourceGroupName, "")
 }
 
-func TstCommon(apikey string, token string, accountID string, region string, resourceGroupName string, resourceGroupID string) error {
-	if err := SetGlobalContext(apikey, token, accountID, region, resourceGroupName, resourceGroupID, "", true); err != nil { // todo
+func TstCommon(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string) error {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, "", true); err != nil { // todo
 		return err
 	}
 	serviceInstances, err := List(false)
@@ -1007,11 +1403,11 @@ func Tag(apikey, resourceGroup string) error {
 	force := false
 	verbose := false
 	save := true
-	return TagCommon(apikey, "", "", region, resourceGroupName, "", vpcid, crn, force, verbose, save)
+	return TagCommon(Credentials{Apikey: apikey}, "", region, resourceGroupName, "", vpcid, crn, force, verbose, save)
 }
 
-func TagCommon(apikey string, token string, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, crn string, force bool, verbose, save bool) error {
-	if err := SetGlobalContext(apikey, token, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil { // todo
+func TagCommon(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, crn string, force bool, verbose, save bool) error {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil { // todo
 		return err
 	}
 
@@ -1022,21 +1418,16 @@ func TagCommon(apikey string, token string, accountID string, region string, res
 
 	// filter the list of service instanes to intersect with the ones passed by params
 	serviceInstances, err = parameterServiceInstances(serviceInstances, save, crn)
+	serviceInstances = pruneProtected(serviceInstances)
 
 	lsOutput(serviceInstances, os.Stdout, false)
 	if !force {
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Remove these resources? Y/n: ")
-		text, _ := reader.ReadString('\n')
-		text = strings.ToLower(strings.TrimSpace(text))
-		fmt.Println(text)
-		force = len(text) == 0 || strings.HasPrefix(text, "y")
+		force = confirmRemove()
 	}
 
 	if !force {
 		return nil
 	}
 
-	RmServiceInstances(serviceInstances)
-	return nil
+	return RmServiceInstances(serviceInstances)
 }
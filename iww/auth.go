@@ -0,0 +1,126 @@
+package iww
+
+// authentication: api key, bearer token, an authenticator from the caller or an IAM trusted profile
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// Credentials describe how to authenticate.  Provide one of Apikey, Token, Authenticator or a trusted profile.
+//
+// A trusted profile is assumed using a compute resource token.  The token is read from the CRTokenFile, a
+// kubernetes projected service account token for example, or from the VSI metadata service when VpcInstance is true.
+//
+// The Apikey can assume the trusted profile named AssumeProfileName in another account, AssumeAccountID, see
+// AccountCredentials.
+type Credentials struct {
+	Apikey        string
+	Token         string
+	Authenticator core.Authenticator
+	// trusted profile
+	TrustedProfileID   string
+	TrustedProfileName string
+	TrustedProfileCRN  string
+	CRTokenFile        string
+	VpcInstance        bool
+	// trusted profile in another account assumed with the api key
+	AssumeProfileName string
+	AssumeAccountID   string
+}
+
+func (creds Credentials) isTrustedProfile() bool {
+	return creds.TrustedProfileID != "" || creds.TrustedProfileName != "" || creds.TrustedProfileCRN != "" || creds.CRTokenFile != "" || creds.VpcInstance
+}
+
+// newAuthenticator returns the authenticator for the credentials
+func (creds Credentials) newAuthenticator() (core.Authenticator, error) {
+	provided := 0
+	for _, isProvided := range []bool{creds.Apikey != "", creds.Token != "", creds.Authenticator != nil, creds.isTrustedProfile()} {
+		if isProvided {
+			provided++
+		}
+	}
+	if provided != 1 {
+		return nil, errors.New("one of apikey, token or trusted profile must be provided (not more than one)")
+	}
+	if creds.AssumeAccountID != "" && (creds.Apikey == "" || creds.AssumeProfileName == "") {
+		return nil, errors.New("assuming a trusted profile in account " + creds.AssumeAccountID + " requires an apikey and a trusted profile name")
+	}
+	switch {
+	case creds.Authenticator != nil:
+		return creds.Authenticator, nil
+	case creds.Token != "":
+		return core.NewBearerTokenAuthenticator(creds.Token)
+	case creds.Apikey != "" && creds.AssumeAccountID != "":
+		return core.NewIamAssumeAuthenticatorBuilder().
+			SetApiKey(creds.Apikey).
+			SetIAMProfileName(creds.AssumeProfileName).
+			SetIAMAccountID(creds.AssumeAccountID).
+			Build()
+	case creds.Apikey != "":
+		return &core.IamAuthenticator{ApiKey: creds.Apikey}, nil
+	case creds.VpcInstance:
+		if creds.TrustedProfileName != "" {
+			return nil, errors.New("the vpc instance metadata service requires a trusted profile id or crn, not a name")
+		}
+		return core.NewVpcInstanceAuthenticatorBuilder().
+			SetIAMProfileID(creds.TrustedProfileID).
+			SetIAMProfileCRN(creds.TrustedProfileCRN).
+			Build()
+	default:
+		if creds.TrustedProfileCRN != "" {
+			return nil, errors.New("a compute resource token file requires a trusted profile id or name, not a crn")
+		}
+		return core.NewContainerAuthenticatorBuilder().
+			SetIAMProfileID(creds.TrustedProfileID).
+			SetIAMProfileName(creds.TrustedProfileName).
+			SetCRTokenFilename(creds.CRTokenFile).
+			Build()
+	}
+}
+
+// bearerToken returns the current access token of the authenticator, it is refreshed by the authenticator as needed
+func bearerToken(authenticator core.Authenticator) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, "https://iam.cloud.ibm.com", nil)
+	if err != nil {
+		return "", err
+	}
+	if err = authenticator.Authenticate(request); err != nil {
+		return "", err
+	}
+	authorization := request.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return "", errors.New("authenticator did not provide a bearer token")
+	}
+	return strings.TrimPrefix(authorization, "Bearer "), nil
+}
+
+// accountFromToken returns the account id in an IAM access token
+func accountFromToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", err
+	}
+	claims := struct {
+		Account struct {
+			Bss string `json:"bss"`
+		} `json:"account"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", err
+	}
+	if claims.Account.Bss == "" {
+		return "", errors.New("access token does not contain an account")
+	}
+	return claims.Account.Bss, nil
+}
@@ -0,0 +1,43 @@
+package iww
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthAccountFromToken(t *testing.T) {
+	assert := assert.New(t)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"account":{"bss":"713c783d9a507a53135fe6793c37cc74"}}`))
+	account, err := accountFromToken("header." + payload + ".signature")
+	assert.Nil(err)
+	assert.Equal("713c783d9a507a53135fe6793c37cc74", account)
+
+	_, err = accountFromToken("not-a-jwt")
+	assert.NotNil(err)
+	payload = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"x"}`))
+	_, err = accountFromToken("header." + payload + ".signature")
+	assert.NotNil(err)
+}
+
+func TestAuthNewAuthenticator(t *testing.T) {
+	assert := assert.New(t)
+	_, err := Credentials{}.newAuthenticator()
+	assert.NotNil(err)
+	_, err = Credentials{Apikey: "a", Token: "t"}.newAuthenticator()
+	assert.NotNil(err)
+	authenticator, err := Credentials{Apikey: "a"}.newAuthenticator()
+	assert.Nil(err)
+	assert.Equal("iam", authenticator.AuthenticationType())
+	authenticator, err = Credentials{TrustedProfileID: "Profile-1", CRTokenFile: "/tmp/token"}.newAuthenticator()
+	assert.Nil(err)
+	assert.Equal("container", authenticator.AuthenticationType())
+	authenticator, err = Credentials{TrustedProfileID: "Profile-1", VpcInstance: true}.newAuthenticator()
+	assert.Nil(err)
+	assert.Equal("vpc", authenticator.AuthenticationType())
+	_, err = Credentials{TrustedProfileName: "name", VpcInstance: true}.newAuthenticator()
+	assert.NotNil(err)
+	_, err = Credentials{TrustedProfileCRN: "crn:v1:x", CRTokenFile: "/tmp/token"}.newAuthenticator()
+	assert.NotNil(err)
+}
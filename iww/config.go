@@ -0,0 +1,250 @@
+package iww
+
+/*
+Profiles are read from ~/.config/iww/config.yaml (or the file in the IWW_CONFIG environment variable):
+
+	default_profile: sandbox
+	profiles:
+	  sandbox:
+	    apikey_env: SANDBOX_APIKEY
+	    # or instead of an api key, assume a trusted profile:
+	    # trusted_profile:
+	    #   profile_id: Profile-0a4e...
+	    #   cr_token_file: /var/run/secrets/tokens/sa-token    # or vpc_instance: true on a VSI
+	    account: 713c783d9a507a53135fe6793c37cc74
+	    # trusted profile assumed with the api key in the other accounts of --account and --enterprise
+	    assume_profile: iww-cleanup
+	    regions: [us-south, us-east]
+	    resource_group: default
+	    output: text
+	    save_file: /tmp/sandbox.txt
+	    protected:
+	      - name: "^prod-"
+	      - crn: ":kms:"
+	    endpoints:
+	      vpc: https://<region>.private.iaas.cloud.ibm.com/v1
+*/
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultApikeyEnv = "APIKEY"
+
+type Config struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of defaults, everything is optional
+type Profile struct {
+	Name           string            `yaml:"-"`
+	ApikeyEnv      string            `yaml:"apikey_env"` // name of the environment variable holding the api key
+	TrustedProfile *TrustedProfile   `yaml:"trusted_profile"`
+	Account        string            `yaml:"account"`
+	AssumeProfile  string            `yaml:"assume_profile"` // trusted profile name assumed in the other accounts
+	Regions        []string          `yaml:"regions"`
+	ResourceGroup  string            `yaml:"resource_group"`
+	Output         string            `yaml:"output"` // text, json or tree
+	SaveFile       string            `yaml:"save_file"`
+	Protected      []ProtectedRule   `yaml:"protected"`
+	Endpoints      map[string]string `yaml:"endpoints"`      // service name to endpoint, <region> is replaced
+	StopInstances  bool              `yaml:"stop_instances"` // rm stops vpc instances before deleting them
+	EmptyBuckets   bool              `yaml:"empty_buckets"`  // rm deletes the objects in cos buckets before deleting them
+}
+
+// TrustedProfile identifies an IAM trusted profile to assume instead of using an api key.  The compute resource
+// token is read from the cr_token_file or from the VSI metadata service if vpc_instance is true
+type TrustedProfile struct {
+	ProfileID   string `yaml:"profile_id"`
+	ProfileName string `yaml:"profile_name"`
+	ProfileCRN  string `yaml:"profile_crn"`
+	CRTokenFile string `yaml:"cr_token_file"`
+	VpcInstance bool   `yaml:"vpc_instance"`
+}
+
+// Credentials for the trusted profile
+func (trustedProfile *TrustedProfile) Credentials() Credentials {
+	return Credentials{
+		TrustedProfileID:   trustedProfile.ProfileID,
+		TrustedProfileName: trustedProfile.ProfileName,
+		TrustedProfileCRN:  trustedProfile.ProfileCRN,
+		CRTokenFile:        trustedProfile.CRTokenFile,
+		VpcInstance:        trustedProfile.VpcInstance,
+	}
+}
+
+// ProtectedRule matches resources that rm must never remove.  Each non empty field is a regular expression
+// and all of them must match
+type ProtectedRule struct {
+	Crn           string `yaml:"crn"`
+	Name          string `yaml:"name"`
+	ResourceGroup string `yaml:"resource_group"`
+}
+
+// ConfigFileName returns the configuration file name, it may not exist
+func ConfigFileName() string {
+	if fileName := os.Getenv("IWW_CONFIG"); fileName != "" {
+		return fileName
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "iww", "config.yaml")
+}
+
+// LoadConfig reads the config file.  A missing file is an empty configuration
+func LoadConfig(fileName string) (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}}
+	if fileName == "" {
+		return config, nil
+	}
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, config); err != nil {
+		return nil, errors.New("config file " + fileName + ": " + err.Error())
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	for name, profile := range config.Profiles {
+		if profile == nil {
+			profile = &Profile{}
+			config.Profiles[name] = profile
+		}
+		profile.Name = name
+		if err = profile.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// Profile returns the named profile, the default profile if name is empty or nil if there is no default
+func (config *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = config.DefaultProfile
+		if name == "" {
+			return nil, nil
+		}
+	}
+	if profile, ok := config.Profiles[name]; ok {
+		return profile, nil
+	}
+	return nil, errors.New("profile not found in config file, profile: " + name)
+}
+
+// AccountProfile returns the first profile, by name, for the account or nil if there is none
+func (config *Config) AccountProfile(accountID string) *Profile {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if profile := config.Profiles[name]; accountID != "" && profile.Account == accountID {
+			return profile
+		}
+	}
+	return nil
+}
+
+func (profile *Profile) validate() error {
+	switch profile.Output {
+	case "", OutputText, OutputJson, OutputTree:
+	default:
+		return errors.New("profile " + profile.Name + ": output must be " + OutputText + ", " + OutputJson + " or " + OutputTree + ", not: " + profile.Output)
+	}
+	if profile.ApikeyEnv != "" && profile.TrustedProfile != nil {
+		return errors.New("profile " + profile.Name + ": apikey_env and trusted_profile can not both be provided")
+	}
+	if profile.TrustedProfile != nil && !profile.TrustedProfile.Credentials().isTrustedProfile() {
+		return errors.New("profile " + profile.Name + ": trusted_profile is empty")
+	}
+	for _, rule := range profile.Protected {
+		if _, err := rule.compile(); err != nil {
+			return errors.New("profile " + profile.Name + ": protected: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// Apikey returns the api key from the environment variable named in the profile, APIKEY by default
+func (profile *Profile) Apikey() string {
+	if profile == nil || profile.ApikeyEnv == "" {
+		return os.Getenv(defaultApikeyEnv)
+	}
+	return os.Getenv(profile.ApikeyEnv)
+}
+
+// Region returns the regions as a comma separated string, the format expected by the region flag
+func (profile *Profile) Region() string {
+	if profile == nil {
+		return ""
+	}
+	return strings.Join(profile.Regions, ",")
+}
+
+// activeProfile is the profile used when the global context is created, see UseProfile
+var activeProfile *Profile
+
+// UseProfile must be called before the global context is created to use the profile settings that do not
+// have a corresponding command line flag: output, save file, protected resources and endpoints
+func UseProfile(profile *Profile) {
+	activeProfile = profile
+}
+
+type compiledProtectedRule struct {
+	crn, name, resourceGroup *regexp.Regexp
+}
+
+func compileOptional(expression string) (*regexp.Regexp, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expression)
+}
+
+func (rule ProtectedRule) compile() (*compiledProtectedRule, error) {
+	var err error
+	ret := &compiledProtectedRule{}
+	if rule.Crn == "" && rule.Name == "" && rule.ResourceGroup == "" {
+		return nil, errors.New("rule must have at least one of crn, name or resource_group")
+	}
+	if ret.crn, err = compileOptional(rule.Crn); err != nil {
+		return nil, err
+	}
+	if ret.name, err = compileOptional(rule.Name); err != nil {
+		return nil, err
+	}
+	if ret.resourceGroup, err = compileOptional(rule.ResourceGroup); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (rule *compiledProtectedRule) matches(crn, name, resourceGroup string) bool {
+	if rule.crn != nil && !rule.crn.MatchString(crn) {
+		return false
+	}
+	if rule.name != nil && !rule.name.MatchString(name) {
+		return false
+	}
+	if rule.resourceGroup != nil && !rule.resourceGroup.MatchString(resourceGroup) {
+		return false
+	}
+	return true
+}
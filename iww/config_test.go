@@ -0,0 +1,122 @@
+package iww
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+default_profile: sandbox
+profiles:
+  sandbox:
+    apikey_env: IWW_TEST_SANDBOX_APIKEY
+    regions: [us-south, eu-de]
+    resource_group: default
+    output: json
+    protected:
+      - name: "^prod-"
+      - crn: ":kms:"
+        resource_group: "^shared$"
+  empty:
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestConfigProfiles(t *testing.T) {
+	assert := assert.New(t)
+	config, err := LoadConfig(writeTestConfig(t, testConfig))
+	assert.NoError(err)
+
+	profile, err := config.Profile("")
+	assert.NoError(err)
+	assert.Equal("sandbox", profile.Name)
+	assert.Equal("us-south,eu-de", profile.Region())
+	assert.Equal(OutputJson, profile.Output)
+	t.Setenv("IWW_TEST_SANDBOX_APIKEY", "secret")
+	assert.Equal("secret", profile.Apikey())
+
+	empty, err := config.Profile("empty")
+	assert.NoError(err)
+	assert.Equal("", empty.Region())
+
+	_, err = config.Profile("missing")
+	assert.Error(err)
+}
+
+func TestConfigAccountProfile(t *testing.T) {
+	assert := assert.New(t)
+	config, err := LoadConfig(writeTestConfig(t, `
+profiles:
+  b:
+    account: "222"
+  a:
+    account: "222"
+  c:
+    account: "333"
+    assume_profile: iww-cleanup
+`))
+	assert.NoError(err)
+	assert.Equal("a", config.AccountProfile("222").Name)
+	assert.Equal("iww-cleanup", config.AccountProfile("333").AssumeProfile)
+	assert.Nil(config.AccountProfile("444"))
+	assert.Nil(config.AccountProfile(""))
+}
+
+func TestConfigMissingFile(t *testing.T) {
+	assert := assert.New(t)
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "none.yaml"))
+	assert.NoError(err)
+	profile, err := config.Profile("")
+	assert.NoError(err)
+	assert.Nil(profile)
+}
+
+func TestConfigInvalid(t *testing.T) {
+	assert := assert.New(t)
+	_, err := LoadConfig(writeTestConfig(t, "profiles:\n  bad:\n    output: xml\n"))
+	assert.Error(err)
+	_, err = LoadConfig(writeTestConfig(t, "profiles:\n  bad:\n    protected:\n      - name: \"(\"\n"))
+	assert.Error(err)
+}
+
+func TestConfigProtected(t *testing.T) {
+	assert := assert.New(t)
+	config, err := LoadConfig(writeTestConfig(t, testConfig))
+	assert.NoError(err)
+	profile, _ := config.Profile("sandbox")
+	context := &Context{}
+	assert.NoError(context.applyProfile(profile))
+	assert.Equal(OutputJson, context.outputFormat)
+	assert.Equal(defaultSaveFile, context.saveFile)
+
+	matches := func(crn, name, resourceGroup string) bool {
+		for _, rule := range context.protected {
+			if rule.matches(crn, name, resourceGroup) {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(matches("crn:v1:bluemix:public:is:us-south:a/x::vpc:r006-1", "prod-vpc", "default"))
+	assert.False(matches("crn:v1:bluemix:public:is:us-south:a/x::vpc:r006-1", "dev-vpc", "default"))
+	assert.True(matches("crn:v1:bluemix:public:kms:us-south:a/x:1::", "keys", "shared"))
+	assert.False(matches("crn:v1:bluemix:public:kms:us-south:a/x:1::", "keys", "default"))
+}
+
+func TestSplitRegions(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{}, splitRegions(""))
+	assert.Equal([]string{"us-south", "eu-de"}, splitRegions("us-south, eu-de,"))
+	context := &Context{regions: splitRegions("us-south,eu-de")}
+	assert.True(context.inRegion("eu-de"))
+	assert.False(context.inRegion("jp-tok"))
+}
@@ -0,0 +1,287 @@
+package iww
+
+// cloud object storage buckets are sub instances of the cos instance, read through the s3 compatible api.  The
+// resource controller will not delete a cos instance that has buckets so the instance waits for its buckets.  A
+// bucket must be empty before it is deleted, the objects, versions and delete markers are only deleted with
+// rm --empty-buckets, see Profile.EmptyBuckets
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+const cosResourceType = "cloud-object-storage"
+
+// cosListLocation is the cross region location used to list the buckets of an instance in all locations
+const cosListLocation = "us"
+
+type cosBucket struct {
+	Name               string `xml:"Name"`
+	CreationDate       string `xml:"CreationDate"`
+	LocationConstraint string `xml:"LocationConstraint"` // like us-south-smart, the location and the storage class
+}
+
+type cosListBucketsResult struct {
+	Buckets []cosBucket `xml:"Buckets>Bucket"`
+}
+
+type cosObject struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+type cosListObjectsResult struct {
+	Contents              []cosObject `xml:"Contents"`
+	IsTruncated           bool        `xml:"IsTruncated"`
+	NextContinuationToken string      `xml:"NextContinuationToken"`
+}
+
+type cosObjectVersion struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId"`
+}
+
+type cosListVersionsResult struct {
+	Versions            []cosObjectVersion `xml:"Version"`
+	DeleteMarkers       []cosObjectVersion `xml:"DeleteMarker"`
+	IsTruncated         bool               `xml:"IsTruncated"`
+	NextKeyMarker       string             `xml:"NextKeyMarker"`
+	NextVersionIDMarker string             `xml:"NextVersionIdMarker"`
+}
+
+type cosDelete struct {
+	XMLName xml.Name           `xml:"Delete"`
+	Quiet   bool               `xml:"Quiet"`
+	Objects []cosObjectVersion `xml:"Object"`
+}
+
+// cosLocation is the location of the endpoint of a bucket: us-south-smart is us-south, us-standard is us
+func cosLocation(locationConstraint string) string {
+	if i := strings.LastIndex(locationConstraint, "-"); i > 0 {
+		return locationConstraint[:i]
+	}
+	return locationConstraint
+}
+
+// cosClient is the part of the s3 compatible api used by iww for one cos instance and location
+type cosClient struct {
+	service    *core.BaseService
+	instanceID string
+}
+
+func (context *Context) getCosClient(instanceID string, location string) (*cosClient, error) {
+	service, err := core.NewBaseService(&core.ServiceOptions{
+		Authenticator: context.authenticator,
+		URL:           context.endpoint("cos", "https://s3.<region>.cloud-object-storage.appdomain.cloud", location),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cosClient{service: service, instanceID: instanceID}, nil
+}
+
+// request sends the request and unmarshals the xml response into result if it is not nil
+func (client *cosClient) request(method string, path string, query map[string]string, body []byte, result interface{}) (*core.DetailedResponse, error) {
+	builder := core.NewRequestBuilder(method)
+	if _, err := builder.ResolveRequestURL(client.service.GetServiceURL(), path, nil); err != nil {
+		return nil, err
+	}
+	builder.AddHeader("ibm-service-instance-id", client.instanceID)
+	for key, value := range query {
+		builder.AddQuery(key, value)
+	}
+	if body != nil {
+		sum := md5.Sum(body)
+		builder.AddHeader("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		builder.AddHeader("Content-Type", "application/xml")
+		if _, err := builder.SetBodyContent("application/xml", nil, nil, bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+	}
+	request, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	var responseBody []byte
+	response, err := client.service.Request(request, &responseBody)
+	if err != nil || result == nil || len(responseBody) == 0 {
+		return response, err
+	}
+	return response, xml.Unmarshal(responseBody, result)
+}
+
+func (client *cosClient) buckets() ([]cosBucket, error) {
+	result := &cosListBucketsResult{}
+	_, err := client.request(core.GET, "/", map[string]string{"extended": ""}, nil, result)
+	return result.Buckets, err
+}
+
+func (client *cosClient) headBucket(bucket string) (*core.DetailedResponse, error) {
+	return client.request(core.HEAD, "/"+bucket, nil, nil, nil)
+}
+
+// objects returns the number of objects in the bucket and their total size, truncated is true if there are more
+func (client *cosClient) objects(bucket string) (count int, size int64, truncated bool, err error) {
+	query := map[string]string{"list-type": "2"}
+	// 100 pages of 1000 objects at most, avoid reading a huge bucket
+	for i := 0; i < 100; i++ {
+		result := &cosListObjectsResult{}
+		if _, err := client.request(core.GET, "/"+bucket, query, nil, result); err != nil {
+			return 0, 0, false, err
+		}
+		for _, object := range result.Contents {
+			count++
+			size += object.Size
+		}
+		truncated = result.IsTruncated
+		if !truncated {
+			break
+		}
+		query["continuation-token"] = result.NextContinuationToken
+	}
+	return count, size, truncated, nil
+}
+
+// emptyBucket deletes the objects, versions and delete markers of the bucket a page at a time
+func (client *cosClient) emptyBucket(bucket string) error {
+	query := map[string]string{"versions": ""}
+	for {
+		result := &cosListVersionsResult{}
+		if _, err := client.request(core.GET, "/"+bucket, query, nil, result); err != nil {
+			return err
+		}
+		objects := append(result.Versions, result.DeleteMarkers...)
+		if len(objects) > 0 {
+			MustGlobalContext().verboseLogger.Print("delete objects:", len(objects), " bucket:", bucket)
+			body, err := xml.Marshal(cosDelete{Quiet: true, Objects: objects})
+			if err != nil {
+				return err
+			}
+			if _, err := client.request(core.POST, "/"+bucket, map[string]string{"delete": ""}, body, nil); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		query["key-marker"] = result.NextKeyMarker
+		query["version-id-marker"] = result.NextVersionIDMarker
+	}
+}
+
+// --------------------------------------
+type ResourceFinderCos struct{}
+
+// Find adds the buckets of each cos instance, the instance waits for its buckets
+func (finder ResourceFinderCos) Find(wrappedResourceInstances []*ResourceInstanceWrapper) (moreInstanceWrappers []*ResourceInstanceWrapper, err error) {
+	MustGlobalContext().verboseLogger.Println("find ResourceFinderCos")
+	moreInstanceWrappers = wrappedResourceInstances
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType != cosResourceType {
+			continue
+		}
+		client, err := MustGlobalContext().getCosClient(ri.crn.id, cosListLocation)
+		if err != nil {
+			return nil, err
+		}
+		buckets, err := client.buckets()
+		if err != nil {
+			log.Print("ResourceFinderCos, list buckets crn:", ri.crn.Crn, " err:", err)
+			continue
+		}
+		for _, bucket := range buckets {
+			name := bucket.Name
+			bucketInstance := NewSubInstance(ri, "bucket", bucket.Name, &name, &CosBucketOperations{locationConstraint: bucket.LocationConstraint})
+			ri.waitFor = append(ri.waitFor, bucketInstance)
+			moreInstanceWrappers = append(moreInstanceWrappers, bucketInstance)
+		}
+	}
+	return moreInstanceWrappers, nil
+}
+
+// --------------------------------------
+type CosBucketOperations struct {
+	locationConstraint string
+	objects            int   // from the last fetch
+	size               int64 // bytes, from the last fetch
+	truncated          bool  // there are more objects than counted
+	notEmptyReported   bool  // the bucket is not empty has been logged
+}
+
+func (s *CosBucketOperations) client(ri *ResourceInstanceWrapper) (*cosClient, error) {
+	return MustGlobalContext().getCosClient(ri.parent.crn.id, cosLocation(s.locationConstraint))
+}
+
+func (s *CosBucketOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := s.client(ri)
+	if err != nil {
+		log.Print("CosBucketOperations.Fetch, getCosClient err:", err)
+		return
+	}
+	bucket := ri.crn.vpcId
+	if response, err := client.headBucket(bucket); err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("CosBucketOperations.Fetch, head bucket:", bucket, " err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	if s.objects, s.size, s.truncated, err = client.objects(bucket); err != nil {
+		log.Print("CosBucketOperations.Fetch, list objects bucket:", bucket, " err:", err)
+	}
+}
+
+func (s *CosBucketOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := s.client(ri)
+	if err != nil {
+		log.Print("CosBucketOperations.Destroy, getCosClient err:", err)
+		return
+	}
+	bucket := ri.crn.vpcId
+	if s.objects > 0 && !MustGlobalContext().emptyBuckets {
+		if !s.notEmptyReported {
+			s.notEmptyReported = true
+			log.Print("bucket is not empty, objects:", s.objectsCount(), " remove them or use rm --empty-buckets, bucket:", bucket)
+		}
+		return
+	}
+	// versions and delete markers are not counted in objects, empty the bucket even if no objects were found
+	if MustGlobalContext().emptyBuckets {
+		if err := client.emptyBucket(bucket); err != nil {
+			log.Print("CosBucketOperations.Destroy, empty bucket:", bucket, " err:", err)
+			return
+		}
+	}
+	if _, err := client.request(core.DELETE, "/"+bucket, nil, nil, nil); err != nil {
+		log.Print("CosBucketOperations.Destroy, delete bucket:", bucket, " err:", err)
+	}
+}
+
+// objectsCount is the number of objects, followed by + if there are more than were counted
+func (s *CosBucketOperations) objectsCount() string {
+	if s.truncated {
+		return strconv.Itoa(s.objects) + "+"
+	}
+	return strconv.Itoa(s.objects)
+}
+
+func (s *CosBucketOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	description := "bucket location:" + s.locationConstraint
+	if !fast {
+		size := strconv.FormatInt(s.size, 10)
+		if s.truncated {
+			size += "+"
+		}
+		description += " objects:" + s.objectsCount() + " size:" + size
+	}
+	return FormatInstance(*ri.Name, description, *ri.crn)
+}
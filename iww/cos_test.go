@@ -0,0 +1,128 @@
+package iww
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// s3StandIn is an s3 compatible stand in for cos with one bucket holding versioned objects
+type s3StandIn struct {
+	bucket        string
+	objects       map[string]int64 // key to size of the current objects
+	versions      []cosObjectVersion
+	deleteMarkers []cosObjectVersion
+}
+
+func (s3 *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	w.Header().Set("Content-Type", "application/xml")
+	if r.URL.Path == "/" {
+		fmt.Fprintf(w, `<ListAllMyBucketsResult><Buckets><Bucket><Name>%s</Name><LocationConstraint>us-south-smart</LocationConstraint></Bucket></Buckets></ListAllMyBucketsResult>`, s3.bucket)
+		return
+	}
+	if s3.bucket == "" || r.URL.Path != "/"+s3.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case r.Method == http.MethodHead:
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		result := cosListObjectsResult{}
+		for key, size := range s3.objects {
+			result.Contents = append(result.Contents, cosObject{Key: key, Size: size})
+		}
+		xml.NewEncoder(w).Encode(struct {
+			XMLName xml.Name `xml:"ListBucketResult"`
+			cosListObjectsResult
+		}{cosListObjectsResult: result})
+	case r.Method == http.MethodGet && query.Has("versions"):
+		xml.NewEncoder(w).Encode(struct {
+			XMLName xml.Name `xml:"ListVersionsResult"`
+			cosListVersionsResult
+		}{cosListVersionsResult: cosListVersionsResult{Versions: s3.versions, DeleteMarkers: s3.deleteMarkers}})
+	case r.Method == http.MethodPost && query.Has("delete"):
+		if r.Header.Get("Content-MD5") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		deletes := cosDelete{}
+		body, _ := io.ReadAll(r.Body)
+		xml.Unmarshal(body, &deletes)
+		if len(deletes.Objects) == len(s3.versions)+len(s3.deleteMarkers) {
+			s3.objects, s3.versions, s3.deleteMarkers = map[string]int64{}, nil, nil
+		}
+		io.WriteString(w, `<DeleteResult></DeleteResult>`)
+	case r.Method == http.MethodDelete:
+		if len(s3.versions)+len(s3.deleteMarkers) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s3.bucket = ""
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestCosBuckets(t *testing.T) {
+	assert := assert.New(t)
+	s3 := &s3StandIn{
+		bucket:        "b1",
+		objects:       map[string]int64{"a": 100, "b/c": 24},
+		versions:      []cosObjectVersion{{Key: "a", VersionID: "1"}, {Key: "a", VersionID: "2"}, {Key: "b/c", VersionID: "null"}},
+		deleteMarkers: []cosObjectVersion{{Key: "d", VersionID: "3"}},
+	}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{
+		verboseLogger: log.New(io.Discard, "", 0),
+		authenticator: &core.NoAuthAuthenticator{},
+		endpoints:     map[string]string{"cos": server.URL},
+	}
+	defer func() { GlobalContext = saved }()
+
+	assert.Equal("us-south", cosLocation("us-south-smart"))
+	assert.Equal("us", cosLocation("us-standard"))
+
+	group := "g"
+	name := "cos"
+	instance := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:cloud-object-storage:global:a/111:guid::"), &group, &name)
+	ris, err := ResourceFinderCos{}.Find([]*ResourceInstanceWrapper{instance})
+	assert.Nil(err)
+	assert.Len(ris, 2)
+	bucket := ris[1]
+	assert.Equal(instance, bucket.parent)
+	assert.Equal([]*ResourceInstanceWrapper{bucket}, instance.waitFor)
+
+	bucket.Fetch()
+	assert.Equal(SIStateExists, bucket.state)
+	assert.Contains(bucket.FormatInstance(false), "bucket location:us-south-smart objects:2 size:124 ")
+
+	// not emptied without --empty-buckets, reported once
+	bucket.Destroy()
+	bucket.Fetch()
+	assert.Equal(SIStateExists, bucket.state)
+	assert.True(bucket.operations.(*CosBucketOperations).notEmptyReported)
+
+	GlobalContext.emptyBuckets = true
+	bucket.Destroy()
+	bucket.Fetch()
+	assert.Equal(SIStateDeleted, bucket.state)
+}
+
+func TestCosObjectsTruncated(t *testing.T) {
+	assert := assert.New(t)
+	name := "bucket"
+	bucket := NewResourceInstanceWrapper(NewFakeCrn("cloud-object-storage", "guid", "bucket", name, "global"), nil, &name)
+	operations := &CosBucketOperations{locationConstraint: "us-south-smart", objects: 100000, size: 5, truncated: true}
+	assert.Contains(operations.FormatInstance(bucket, false), "objects:100000+ size:5+")
+}
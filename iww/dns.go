@@ -30,6 +30,7 @@ func isDns(ri *ResourceInstanceWrapper) bool {
 func (context *Context) getDnssvcsClient() (client *dnssvcsv1.DnsSvcsV1, err error) {
 	return dnssvcsv1.NewDnsSvcsV1(&dnssvcsv1.DnsSvcsV1Options{
 		Authenticator: context.authenticator,
+		URL:           context.endpoint("dns", dnssvcsv1.DefaultServiceURL, ""),
 	})
 }
 
@@ -74,21 +75,26 @@ func readDnsResources(currentResourceInstances []*ResourceInstanceWrapper) ([]*R
 			}
 
 			for _, sub := range result.Dnszones {
-				wrappedResourceInstances = append(wrappedResourceInstances, NewSubInstance(ri, "zone", *sub.ID, sub.Name, &Dnszone{}))
+				zone := NewSubInstance(ri, "zone", *sub.ID, sub.Name, &Dnszone{})
+				wrappedResourceInstances = append(wrappedResourceInstances, zone)
 				pns, _, err := client.ListPermittedNetworks(client.NewListPermittedNetworksOptions(ri.crn.id, *sub.ID))
 				if err != nil {
 					return nil, err
 				}
 				for _, pn := range pns.PermittedNetworks {
 					// todo notice the name is actually the ID of the dns zone which is needed to delete, kludge city
-					wrappedResourceInstances = append(wrappedResourceInstances, NewSubInstance(ri, "pn", *pn.ID, sub.ID, &DnsPermittedNetwork{}))
+					permittedNetwork := NewSubInstance(ri, "pn", *pn.ID, sub.ID, &DnsPermittedNetwork{})
+					permittedNetwork.parent = zone
+					wrappedResourceInstances = append(wrappedResourceInstances, permittedNetwork)
 				}
 				lbs, _, err := client.ListLoadBalancers(client.NewListLoadBalancersOptions(ri.crn.id, *sub.ID))
 				if err != nil {
 					return nil, err
 				}
 				for _, lb := range lbs.LoadBalancers {
-					wrappedResourceInstances = append(wrappedResourceInstances, NewSubInstance(ri, "lb", *lb.ID, sub.ID, &DnsLoadBalancer{}))
+					loadBalancer := NewSubInstance(ri, "lb", *lb.ID, sub.ID, &DnsLoadBalancer{})
+					loadBalancer.parent = zone
+					wrappedResourceInstances = append(wrappedResourceInstances, loadBalancer)
 				}
 			}
 		}
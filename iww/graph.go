@@ -0,0 +1,138 @@
+package iww
+
+// graph of the resources and their relations in graphviz dot or mermaid format
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// graph formats
+const (
+	GraphDot     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// Graph lists the resources that match the parameters, same as ls, and writes them as a graph to stdout
+func Graph(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string, format string, verbose bool) error {
+	if format != GraphDot && format != GraphMermaid {
+		return errors.New("graph format must be " + GraphDot + " or " + GraphMermaid + ", not: " + format)
+	}
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, vpcid, verbose); err != nil {
+		return err
+	}
+	wrappedResourceInstances, err := List(false)
+	if err != nil {
+		return err
+	}
+	existing := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range wrappedResourceInstances {
+		if _, ok := ri.operations.(UnimplementedServiceOperations); ok || ri.state == SIStateDeleted {
+			continue
+		}
+		existing = append(existing, ri)
+	}
+	context := MustGlobalContext()
+	return writeGraph(os.Stdout, format, existing, func(id string) string {
+		return context.getResourceGroupName(id, false)
+	})
+}
+
+// graphEdge is drawn from -> to, the direction depends on the kind of relation: a vpc contains a subnet
+// but a volume is attached to an instance and a key belongs to an instance
+type graphEdge struct {
+	from, to *ResourceInstanceWrapper
+	label    string
+}
+
+func graphEdges(relations []relation) []graphEdge {
+	ret := make([]graphEdge, 0)
+	for _, r := range relations {
+		switch r.kind {
+		case relationContains:
+			ret = append(ret, graphEdge{from: r.parent, to: r.child, label: r.kind})
+		default:
+			ret = append(ret, graphEdge{from: r.child, to: r.parent, label: r.kind})
+		}
+	}
+	return ret
+}
+
+func graphNodeLabel(ri *ResourceInstanceWrapper) string {
+	name := ""
+	if ri.Name != nil {
+		name = *ri.Name
+	}
+	return strings.TrimSpace(ri.crn.resourceType+" "+ri.crn.vpcType) + "\n" + name
+}
+
+// writeGraph writes the resources as nodes grouped by resource group and the relations as edges
+func writeGraph(w io.Writer, format string, wrappedResourceInstances []*ResourceInstanceWrapper, resourceGroupName func(id string) string) error {
+	ris := append(RIWs{}, wrappedResourceInstances...)
+	sort.Sort(ris)
+	nodeIDs := make(map[*ResourceInstanceWrapper]string)
+	byGroup := make(map[string]RIWs)
+	groupIDs := make([]string, 0)
+	for i, ri := range ris {
+		nodeIDs[ri] = fmt.Sprint("n", i)
+		groupID := ""
+		if ri.ResourceGroupID != nil {
+			groupID = *ri.ResourceGroupID
+		}
+		if _, ok := byGroup[groupID]; !ok {
+			groupIDs = append(groupIDs, groupID)
+		}
+		byGroup[groupID] = append(byGroup[groupID], ri)
+	}
+	sort.Strings(groupIDs)
+	edges := graphEdges(resourceRelations(ris))
+
+	switch format {
+	case GraphDot:
+		fmt.Fprintln(w, "digraph iww {")
+		fmt.Fprintln(w, "  rankdir=LR;")
+		fmt.Fprintln(w, "  node [shape=box];")
+		for i, groupID := range groupIDs {
+			fmt.Fprintf(w, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(w, "    label=%s;\n", dotQuote("resource group "+firstNonEmpty(resourceGroupName(groupID), groupID)))
+			for _, ri := range byGroup[groupID] {
+				fmt.Fprintf(w, "    %s [label=%s];\n", nodeIDs[ri], dotQuote(graphNodeLabel(ri)))
+			}
+			fmt.Fprintln(w, "  }")
+		}
+		for _, edge := range edges {
+			fmt.Fprintf(w, "  %s -> %s [label=%s];\n", nodeIDs[edge.from], nodeIDs[edge.to], dotQuote(edge.label))
+		}
+		fmt.Fprintln(w, "}")
+	case GraphMermaid:
+		fmt.Fprintln(w, "flowchart LR")
+		for i, groupID := range groupIDs {
+			fmt.Fprintf(w, "  subgraph g%d [%s]\n", i, mermaidQuote("resource group "+firstNonEmpty(resourceGroupName(groupID), groupID)))
+			for _, ri := range byGroup[groupID] {
+				fmt.Fprintf(w, "    %s[%s]\n", nodeIDs[ri], mermaidQuote(graphNodeLabel(ri)))
+			}
+			fmt.Fprintln(w, "  end")
+		}
+		for _, edge := range edges {
+			fmt.Fprintf(w, "  %s -->|%s| %s\n", nodeIDs[edge.from], edge.label, nodeIDs[edge.to])
+		}
+	default:
+		return errors.New("unknown graph format: " + format)
+	}
+	return nil
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + strings.ReplaceAll(s, "\n", "<br/>") + `"`
+}
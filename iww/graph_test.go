@@ -0,0 +1,50 @@
+package iww
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	assert := assert.New(t)
+	group := "g"
+	vpcName, subnetName := "vpc1", `sub"net`
+	vpc := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::vpc:v"), &group, &vpcName)
+	subnet := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::subnet:s"), &group, &subnetName)
+	subnet.operations = &VpcGenericOperation{vpcid: "v"}
+	key := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:kms:us-south:a/111:i:resource-key:k"), &group, nil)
+	key.operations = &ResourceKeyOperations{}
+	key.parent = vpc
+	ris := []*ResourceInstanceWrapper{vpc, subnet, key}
+	resourceGroupName := func(id string) string { return "default" }
+
+	dot := &bytes.Buffer{}
+	assert.Nil(writeGraph(dot, GraphDot, ris, resourceGroupName))
+	assert.Equal(`digraph iww {
+  rankdir=LR;
+  node [shape=box];
+  subgraph cluster_0 {
+    label="resource group default";
+    n0 [label="is subnet\nsub\"net"];
+    n1 [label="is vpc\nvpc1"];
+    n2 [label="kms resource-key\n"];
+  }
+  n1 -> n0 [label="contains"];
+  n2 -> n1 [label="key"];
+}
+`, dot.String())
+
+	mermaid := &bytes.Buffer{}
+	assert.Nil(writeGraph(mermaid, GraphMermaid, ris, resourceGroupName))
+	assert.Equal(`flowchart LR
+  subgraph g0 ["resource group default"]
+    n0["is subnet<br/>sub#quot;net"]
+    n1["is vpc<br/>vpc1"]
+    n2["kms resource-key<br/>"]
+  end
+  n1 -->|contains| n0
+  n2 -->|key| n1
+`, mermaid.String())
+}
@@ -0,0 +1,531 @@
+package iww
+
+// iam service ids with their api keys, access groups and service to service authorization policies.  They are not
+// resource controller resources, they are found through the iam policies that reference the resources in the list:
+//   - an authorization policy is listed if its source or target references them.  A policy that only references the
+//     selected resource group is listed but not removed, see listOnlyOperations
+//   - a service id or an access group is listed if all of its access policies reference them by instance, so a
+//     service id or an access group that also grants access to other resources, or to a whole resource group, is
+//     left alone.  Deleting an access group removes its members
+//
+// They wait for the resources they reference, see ResourceInstanceWrapper.waitFor.  Policies and access groups
+// do not have a crn, the fake crn has the id in the vpcId:
+// crn:v1:bluemix:public:iam-access-management:global:a/ACCOUNT::policy:POLICYID
+
+import (
+	"log"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/platform-services-go-sdk/iamaccessgroupsv2"
+	"github.com/IBM/platform-services-go-sdk/iamidentityv1"
+	"github.com/IBM/platform-services-go-sdk/iampolicymanagementv1"
+)
+
+const (
+	iamTypePolicy      = "policy"
+	iamTypeAccessGroup = "access-group"
+)
+
+func (context *Context) getIamPolicyManagementClient() (*iampolicymanagementv1.IamPolicyManagementV1, error) {
+	return iampolicymanagementv1.NewIamPolicyManagementV1(&iampolicymanagementv1.IamPolicyManagementV1Options{
+		Authenticator: context.authenticator,
+		URL:           context.endpoint("iam_access_management", iampolicymanagementv1.DefaultServiceURL, ""),
+	})
+}
+
+func (context *Context) getIamAccessGroupsClient() (*iamaccessgroupsv2.IamAccessGroupsV2, error) {
+	return iamaccessgroupsv2.NewIamAccessGroupsV2(&iamaccessgroupsv2.IamAccessGroupsV2Options{
+		Authenticator: context.authenticator,
+		URL:           context.endpoint("iam_access_groups", iamaccessgroupsv2.DefaultServiceURL, ""),
+	})
+}
+
+// iamAttribute is a subject or resource attribute of a policy
+type iamAttribute struct {
+	name  string
+	value string
+}
+
+func subjectAttributes(subjects []iampolicymanagementv1.PolicySubject) []iamAttribute {
+	ret := make([]iamAttribute, 0)
+	for _, subject := range subjects {
+		for _, attribute := range subject.Attributes {
+			ret = append(ret, iamAttribute{name: vpcString(attribute.Name), value: vpcString(attribute.Value)})
+		}
+	}
+	return ret
+}
+
+func resourceAttributes(resources []iampolicymanagementv1.PolicyResource) []iamAttribute {
+	ret := make([]iamAttribute, 0)
+	for _, resource := range resources {
+		for _, attribute := range resource.Attributes {
+			ret = append(ret, iamAttribute{name: vpcString(attribute.Name), value: vpcString(attribute.Value)})
+		}
+	}
+	return ret
+}
+
+// iamAttributeValue is the value of the named attribute, "" if not present
+func iamAttributeValue(attributes []iamAttribute, name string) string {
+	for _, attribute := range attributes {
+		if attribute.name == name {
+			return attribute.value
+		}
+	}
+	return ""
+}
+
+// iamReferences finds the resources referenced by policy attributes
+type iamReferences struct {
+	byID            map[string][]*ResourceInstanceWrapper // resource instance guid or vpc resource id
+	resourceGroupID string                                // selected resource group, "" if none
+}
+
+func newIamReferences(wrappedResourceInstances []*ResourceInstanceWrapper, resourceGroupID string) *iamReferences {
+	refs := &iamReferences{
+		byID:            make(map[string][]*ResourceInstanceWrapper),
+		resourceGroupID: resourceGroupID,
+	}
+	for _, ri := range wrappedResourceInstances {
+		if ri.parent != nil {
+			continue
+		}
+		if ri.crn.resourceType == "is" {
+			refs.byID[ri.crn.vpcId] = append(refs.byID[ri.crn.vpcId], ri)
+		} else if ri.crn.id != "" {
+			refs.byID[ri.crn.id] = append(refs.byID[ri.crn.id], ri)
+		}
+	}
+	return refs
+}
+
+// resources returns the resources in the list referenced by the attributes and true if there are any.  The last
+// return is true if the attributes reference the selected resource group
+func (refs *iamReferences) resources(attributes []iamAttribute) ([]*ResourceInstanceWrapper, bool, bool) {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	resourceGroup := false
+	for _, attribute := range attributes {
+		switch {
+		case attribute.name == "accountId" || attribute.name == "serviceName" || attribute.name == "resourceType":
+		case attribute.name == "resourceGroupId":
+			resourceGroup = resourceGroup || (refs.resourceGroupID != "" && attribute.value == refs.resourceGroupID)
+		case attribute.name == "serviceInstance" || attribute.name == "resource" || strings.HasSuffix(attribute.name, "Id"):
+			// vpc policies use attributes like vpcId or imageId
+			ret = append(ret, refs.byID[attribute.value]...)
+		}
+	}
+	return ret, len(ret) > 0, resourceGroup
+}
+
+// iamResourceGroupID is the resource group of the iam resource: the selected group or the group of a resource it
+// references
+func (refs *iamReferences) iamResourceGroupID(referenced []*ResourceInstanceWrapper) *string {
+	if refs.resourceGroupID != "" {
+		return &refs.resourceGroupID
+	}
+	for _, ri := range referenced {
+		if ri.ResourceGroupID != nil {
+			return ri.ResourceGroupID
+		}
+	}
+	return &refs.resourceGroupID
+}
+
+// policySummary is source -> target like: is:image -> kms:guid
+func policySummary(policy *iampolicymanagementv1.Policy) string {
+	summary := func(attributes []iamAttribute) string {
+		ret := firstNonEmpty(iamAttributeValue(attributes, "serviceName"), "*")
+		if detail := firstNonEmpty(iamAttributeValue(attributes, "serviceInstance"), iamAttributeValue(attributes, "resourceType")); detail != "" {
+			ret += ":" + detail
+		}
+		return ret
+	}
+	return summary(subjectAttributes(policy.Subjects)) + " -> " + summary(resourceAttributes(policy.Resources))
+}
+
+// iamSubjectReferences collects what the access policies of each subject reference.  A subject is an iam id, like the
+// iam id of a service id, or an access group id
+type iamSubjectReferences struct {
+	referenced map[string][]*ResourceInstanceWrapper
+	other      map[string]bool // subject has an access policy that references something else
+}
+
+func (subjects *iamSubjectReferences) add(subject string, referenced []*ResourceInstanceWrapper, ok bool) {
+	if !ok {
+		subjects.other[subject] = true
+		return
+	}
+	subjects.referenced[subject] = append(subjects.referenced[subject], referenced...)
+}
+
+// selected returns the resources referenced by the subject and true if all of the access policies of the subject
+// reference the resources
+func (subjects *iamSubjectReferences) selected(subject string) ([]*ResourceInstanceWrapper, bool) {
+	referenced, ok := subjects.referenced[subject]
+	return referenced, ok && !subjects.other[subject]
+}
+
+// --------------------------------------
+type ResourceFinderIam struct{}
+
+func (finder ResourceFinderIam) Find(wrappedResourceInstances []*ResourceInstanceWrapper) (moreInstanceWrappers []*ResourceInstanceWrapper, err error) {
+	context := MustGlobalContext()
+	context.verboseLogger.Println("find ResourceFinderIam")
+	if len(wrappedResourceInstances) == 0 && context.resourceGroupID == "" {
+		return wrappedResourceInstances, nil
+	}
+	policyClient, err := context.getIamPolicyManagementClient()
+	if err != nil {
+		return nil, err
+	}
+	policies, _, err := policyClient.ListPolicies(policyClient.NewListPoliciesOptions(context.accountID))
+	if err != nil {
+		// reading policies needs the viewer role on the iam access management service, do not fail ls
+		log.Print("ResourceFinderIam, ListPolicies err:", err)
+		return wrappedResourceInstances, nil
+	}
+	refs := newIamReferences(wrappedResourceInstances, context.resourceGroupID)
+	serviceIDs := &iamSubjectReferences{referenced: map[string][]*ResourceInstanceWrapper{}, other: map[string]bool{}}
+	accessGroups := &iamSubjectReferences{referenced: map[string][]*ResourceInstanceWrapper{}, other: map[string]bool{}}
+	moreInstanceWrappers = wrappedResourceInstances
+	for i := range policies.Policies {
+		policy := &policies.Policies[i]
+		if vpcString(policy.State) == iampolicymanagementv1.PolicyStateDeletedConst {
+			continue
+		}
+		targets, targetOk, targetGroup := refs.resources(resourceAttributes(policy.Resources))
+		switch vpcString(policy.Type) {
+		case iampolicymanagementv1.ListPoliciesOptionsTypeAuthorizationConst:
+			sources, sourceOk, sourceGroup := refs.resources(subjectAttributes(policy.Subjects))
+			if !sourceOk && !targetOk && !sourceGroup && !targetGroup {
+				continue
+			}
+			referenced := append(sources, targets...)
+			name := policySummary(policy)
+			ri := NewResourceInstanceWrapper(NewFakeCrn("iam-access-management", "", iamTypePolicy, vpcString(policy.ID), "global"), refs.iamResourceGroupID(referenced), &name)
+			ri.operations = &AuthorizationPolicyOperations{resourceGroupOnly: !sourceOk && !targetOk}
+			ri.resource = policy
+			ri.waitFor = referenced
+			moreInstanceWrappers = append(moreInstanceWrappers, ri)
+		case iampolicymanagementv1.ListPoliciesOptionsTypeAccessConst:
+			for _, attribute := range subjectAttributes(policy.Subjects) {
+				switch {
+				case attribute.name == "iam_id" && strings.HasPrefix(attribute.value, "iam-ServiceId-"):
+					serviceIDs.add(attribute.value, targets, targetOk)
+				case attribute.name == "access_group_id":
+					accessGroups.add(attribute.value, targets, targetOk)
+				}
+			}
+		}
+	}
+	serviceIDInstances, err := findServiceIDs(refs, serviceIDs)
+	if err != nil {
+		log.Print("ResourceFinderIam, list service ids err:", err)
+	}
+	accessGroupInstances, err := findAccessGroups(refs, accessGroups)
+	if err != nil {
+		log.Print("ResourceFinderIam, list access groups err:", err)
+	}
+	moreInstanceWrappers = append(moreInstanceWrappers, serviceIDInstances...)
+	moreInstanceWrappers = append(moreInstanceWrappers, accessGroupInstances...)
+	return moreInstanceWrappers, nil
+}
+
+// findServiceIDs returns the selected service ids and their api keys, a service id waits for its api keys
+func findServiceIDs(refs *iamReferences, subjects *iamSubjectReferences) ([]*ResourceInstanceWrapper, error) {
+	context := MustGlobalContext()
+	ret := make([]*ResourceInstanceWrapper, 0)
+	if len(subjects.referenced) == 0 {
+		return ret, nil
+	}
+	client, err := context.getIamClient()
+	if err != nil {
+		return nil, err
+	}
+	options := client.NewListServiceIdsOptions()
+	options.SetAccountID(context.accountID)
+	// 100 times through max, avoid infinite loop
+	for i := 0; i < 100; i++ {
+		list, _, err := client.ListServiceIds(options)
+		if err != nil {
+			return nil, err
+		}
+		for j := range list.Serviceids {
+			serviceID := &list.Serviceids[j]
+			referenced, ok := subjects.selected(vpcString(serviceID.IamID))
+			if !ok {
+				continue
+			}
+			ri := NewResourceInstanceWrapper(NewCrn(*serviceID.CRN), refs.iamResourceGroupID(referenced), serviceID.Name)
+			ri.operations = &ServiceIDOperations{}
+			ri.resource = serviceID
+			ri.waitFor = referenced
+			ret = append(ret, ri)
+			keys, err := findAPIKeys(client, ri, vpcString(serviceID.IamID))
+			if err != nil {
+				return nil, err
+			}
+			ri.waitFor = append(ri.waitFor, keys...)
+			ret = append(ret, keys...)
+		}
+		if list.Next == nil {
+			break
+		}
+		pagetoken, err := core.GetQueryParam(list.Next, "pagetoken")
+		if err != nil || pagetoken == nil {
+			break
+		}
+		options.SetPagetoken(*pagetoken)
+	}
+	return ret, nil
+}
+
+func findAPIKeys(client *iamidentityv1.IamIdentityV1, serviceID *ResourceInstanceWrapper, iamID string) ([]*ResourceInstanceWrapper, error) {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	options := client.NewListAPIKeysOptions()
+	options.SetAccountID(MustGlobalContext().accountID)
+	options.SetIamID(iamID)
+	list, _, err := client.ListAPIKeys(options)
+	if err != nil {
+		return nil, err
+	}
+	for j := range list.Apikeys {
+		apiKey := &list.Apikeys[j]
+		ri := NewResourceInstanceWrapper(NewCrn(*apiKey.CRN), serviceID.ResourceGroupID, apiKey.Name)
+		ri.operations = &APIKeyOperations{}
+		ri.resource = apiKey
+		ri.parent = serviceID
+		ret = append(ret, ri)
+	}
+	return ret, nil
+}
+
+// findAccessGroups returns the selected access groups, the public access group is never selected
+func findAccessGroups(refs *iamReferences, subjects *iamSubjectReferences) ([]*ResourceInstanceWrapper, error) {
+	context := MustGlobalContext()
+	ret := make([]*ResourceInstanceWrapper, 0)
+	if len(subjects.referenced) == 0 {
+		return ret, nil
+	}
+	client, err := context.getIamAccessGroupsClient()
+	if err != nil {
+		return nil, err
+	}
+	options := client.NewListAccessGroupsOptions(context.accountID)
+	options.SetHidePublicAccess(true)
+	limit := int64(100)
+	options.SetLimit(limit)
+	// 100 times through max, avoid infinite loop
+	for i := int64(0); i < 100; i++ {
+		options.SetOffset(i * limit)
+		list, _, err := client.ListAccessGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for j := range list.Groups {
+			group := &list.Groups[j]
+			referenced, ok := subjects.selected(vpcString(group.ID))
+			if !ok {
+				continue
+			}
+			ri := NewResourceInstanceWrapper(NewFakeCrn("iam-groups", "", iamTypeAccessGroup, vpcString(group.ID), "global"), refs.iamResourceGroupID(referenced), group.Name)
+			ri.operations = &AccessGroupOperations{}
+			ri.resource = group
+			ri.waitFor = referenced
+			ret = append(ret, ri)
+		}
+		if int64(len(list.Groups)) < limit {
+			break
+		}
+	}
+	return ret, nil
+}
+
+// --------------------------------------
+type AuthorizationPolicyOperations struct {
+	resourceGroupOnly bool // the policy references the selected resource group but none of the resources
+}
+
+// listOnly keeps a policy for a whole resource group, it may be needed by resources that are not being removed
+func (s *AuthorizationPolicyOperations) listOnly() bool {
+	return s.resourceGroupOnly
+}
+
+func (s *AuthorizationPolicyOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamPolicyManagementClient()
+	if err != nil {
+		log.Print("AuthorizationPolicyOperations.Fetch, getIamPolicyManagementClient err:", err)
+		return
+	}
+	policy, response, err := client.GetPolicy(client.NewGetPolicyOptions(ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("AuthorizationPolicyOperations.Fetch, GetPolicy err:", err)
+		}
+		return
+	}
+	ri.resource = policy
+	if vpcString(policy.State) == iampolicymanagementv1.PolicyStateDeletedConst {
+		ri.state = SIStateDeleted
+	} else {
+		ri.state = SIStateExists
+	}
+}
+
+func (s *AuthorizationPolicyOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamPolicyManagementClient()
+	if err != nil {
+		log.Print("AuthorizationPolicyOperations.Destroy, getIamPolicyManagementClient err:", err)
+		return
+	}
+	if _, err := client.DeletePolicy(client.NewDeletePolicyOptions(ri.crn.vpcId)); err != nil {
+		log.Print("AuthorizationPolicyOperations.Destroy, DeletePolicy err:", err)
+	}
+}
+
+func (s *AuthorizationPolicyOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	roles := make([]string, 0)
+	if policy, ok := ri.resource.(*iampolicymanagementv1.Policy); ok {
+		for _, role := range policy.Roles {
+			roles = append(roles, firstNonEmpty(vpcString(role.DisplayName), vpcString(role.RoleID)))
+		}
+	}
+	description := "authorization roles:" + strings.Join(roles, ",")
+	if s.resourceGroupOnly {
+		description += " resource-group-only"
+	}
+	return FormatInstance(*ri.Name, description, *ri.crn)
+}
+
+// --------------------------------------
+// service ids are unlocked before they are deleted, deleting a service id deletes its access policies
+type ServiceIDOperations struct{}
+
+func (s *ServiceIDOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamClient()
+	if err != nil {
+		log.Print("ServiceIDOperations.Fetch, getIamClient err:", err)
+		return
+	}
+	serviceID, response, err := client.GetServiceID(client.NewGetServiceIDOptions(ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("ServiceIDOperations.Fetch, GetServiceID err:", err)
+		}
+		return
+	}
+	ri.resource = serviceID
+	ri.state = SIStateExists
+}
+
+func (s *ServiceIDOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamClient()
+	if err != nil {
+		log.Print("ServiceIDOperations.Destroy, getIamClient err:", err)
+		return
+	}
+	if serviceID, ok := ri.resource.(*iamidentityv1.ServiceID); ok && serviceID.Locked != nil && *serviceID.Locked {
+		MustGlobalContext().verboseLogger.Print("unlock service id:", ri.crn.Crn)
+		if _, err := client.UnlockServiceID(client.NewUnlockServiceIDOptions(ri.crn.vpcId)); err != nil {
+			log.Print("ServiceIDOperations.Destroy, UnlockServiceID err:", err)
+			return
+		}
+	}
+	if _, err := client.DeleteServiceID(client.NewDeleteServiceIDOptions(ri.crn.vpcId)); err != nil {
+		log.Print("ServiceIDOperations.Destroy, DeleteServiceID err:", err)
+	}
+}
+
+func (s *ServiceIDOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(*ri.Name, "service id", *ri.crn)
+}
+
+// --------------------------------------
+type APIKeyOperations struct{}
+
+func (s *APIKeyOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamClient()
+	if err != nil {
+		log.Print("APIKeyOperations.Fetch, getIamClient err:", err)
+		return
+	}
+	apiKey, response, err := client.GetAPIKey(client.NewGetAPIKeyOptions(ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("APIKeyOperations.Fetch, GetAPIKey err:", err)
+		}
+		return
+	}
+	ri.resource = apiKey
+	ri.state = SIStateExists
+}
+
+func (s *APIKeyOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamClient()
+	if err != nil {
+		log.Print("APIKeyOperations.Destroy, getIamClient err:", err)
+		return
+	}
+	if apiKey, ok := ri.resource.(*iamidentityv1.APIKey); ok && apiKey.Locked != nil && *apiKey.Locked {
+		MustGlobalContext().verboseLogger.Print("unlock api key:", ri.crn.Crn)
+		if _, err := client.UnlockAPIKey(client.NewUnlockAPIKeyOptions(ri.crn.vpcId)); err != nil {
+			log.Print("APIKeyOperations.Destroy, UnlockAPIKey err:", err)
+			return
+		}
+	}
+	if _, err := client.DeleteAPIKey(client.NewDeleteAPIKeyOptions(ri.crn.vpcId)); err != nil {
+		log.Print("APIKeyOperations.Destroy, DeleteAPIKey err:", err)
+	}
+}
+
+func (s *APIKeyOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(*ri.Name, "api key", *ri.crn)
+}
+
+// --------------------------------------
+// access groups are deleted with their members and access policies
+type AccessGroupOperations struct{}
+
+func (s *AccessGroupOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamAccessGroupsClient()
+	if err != nil {
+		log.Print("AccessGroupOperations.Fetch, getIamAccessGroupsClient err:", err)
+		return
+	}
+	group, response, err := client.GetAccessGroup(client.NewGetAccessGroupOptions(ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("AccessGroupOperations.Fetch, GetAccessGroup err:", err)
+		}
+		return
+	}
+	ri.resource = group
+	ri.state = SIStateExists
+}
+
+func (s *AccessGroupOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getIamAccessGroupsClient()
+	if err != nil {
+		log.Print("AccessGroupOperations.Destroy, getIamAccessGroupsClient err:", err)
+		return
+	}
+	options := client.NewDeleteAccessGroupOptions(ri.crn.vpcId)
+	options.SetForce(true)
+	if _, err := client.DeleteAccessGroup(options); err != nil {
+		log.Print("AccessGroupOperations.Destroy, DeleteAccessGroup err:", err)
+	}
+}
+
+func (s *AccessGroupOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(*ri.Name, "access group", *ri.crn)
+}
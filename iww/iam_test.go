@@ -0,0 +1,88 @@
+package iww
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIam(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/policies":
+			io.WriteString(w, `{"policies":[
+				{"id":"p1","type":"authorization","subjects":[{"attributes":[{"name":"serviceName","value":"is"},{"name":"resourceType","value":"image"}]}],
+				 "resources":[{"attributes":[{"name":"serviceName","value":"kms"},{"name":"serviceInstance","value":"k1"}]}],"roles":[{"role_id":"crn:v1:bluemix:public:iam::::serviceRole:Reader","display_name":"Reader"}]},
+				{"id":"p2","type":"authorization","subjects":[{"attributes":[{"name":"serviceName","value":"is"}]}],
+				 "resources":[{"attributes":[{"name":"serviceName","value":"kms"},{"name":"serviceInstance","value":"other"}]}],"roles":[]},
+				{"id":"p3","type":"access","subjects":[{"attributes":[{"name":"iam_id","value":"iam-ServiceId-1"}]}],
+				 "resources":[{"attributes":[{"name":"serviceInstance","value":"k1"}]}],"roles":[]},
+				{"id":"p4","type":"access","subjects":[{"attributes":[{"name":"iam_id","value":"iam-ServiceId-2"}]}],
+				 "resources":[{"attributes":[{"name":"serviceInstance","value":"k1"}]}],"roles":[]},
+				{"id":"p5","type":"access","subjects":[{"attributes":[{"name":"iam_id","value":"iam-ServiceId-2"}]}],
+				 "resources":[{"attributes":[{"name":"serviceInstance","value":"other"}]}],"roles":[]},
+				{"id":"p6","type":"access","subjects":[{"attributes":[{"name":"access_group_id","value":"AccessGroupId-2"}]}],
+				 "resources":[{"attributes":[{"name":"resourceGroupId","value":"rg1"}]}],"roles":[]},
+				{"id":"p7","type":"authorization","subjects":[{"attributes":[{"name":"serviceName","value":"is"}]}],
+				 "resources":[{"attributes":[{"name":"serviceName","value":"kms"},{"name":"resourceGroupId","value":"rg1"}]}],"roles":[]},
+				{"id":"p8","type":"access","subjects":[{"attributes":[{"name":"access_group_id","value":"AccessGroupId-1"}]}],
+				 "resources":[{"attributes":[{"name":"serviceInstance","value":"k1"}]}],"roles":[]}]}`)
+		case "/v1/serviceids/":
+			io.WriteString(w, `{"serviceids":[
+				{"id":"ServiceId-1","iam_id":"iam-ServiceId-1","name":"sid1","locked":false,"crn":"crn:v1:bluemix:public:iam-identity::a/111::serviceid:ServiceId-1"},
+				{"id":"ServiceId-2","iam_id":"iam-ServiceId-2","name":"sid2","locked":false,"crn":"crn:v1:bluemix:public:iam-identity::a/111::serviceid:ServiceId-2"}]}`)
+		case "/v1/apikeys":
+			assert.Equal("iam-ServiceId-1", r.URL.Query().Get("iam_id"))
+			io.WriteString(w, `{"apikeys":[{"id":"ApiKey-1","iam_id":"iam-ServiceId-1","name":"key1","locked":true,"crn":"crn:v1:bluemix:public:iam-identity::a/111::apikey:ApiKey-1"}]}`)
+		case "/v2/groups":
+			io.WriteString(w, `{"limit":100,"offset":0,"total_count":2,"groups":[{"id":"AccessGroupId-1","name":"ag"},{"id":"AccessGroupId-2","name":"ag2"}]}`)
+		default:
+			t.Log("unexpected path:", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{
+		verboseLogger:   log.New(io.Discard, "", 0),
+		authenticator:   &core.NoAuthAuthenticator{},
+		accountID:       "111",
+		resourceGroupID: "rg1",
+		endpoints:       map[string]string{"iam": server.URL, "iam_access_management": server.URL, "iam_access_groups": server.URL},
+	}
+	defer func() { GlobalContext = saved }()
+
+	group := "rg1"
+	name := "kp"
+	kms := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:kms:us-south:a/111:k1::"), &group, &name)
+	ris, err := ResourceFinderIam{}.Find([]*ResourceInstanceWrapper{kms})
+	assert.Nil(err)
+	names := make([]string, 0)
+	for _, ri := range ris[1:] {
+		names = append(names, ri.crn.vpcType+":"+*ri.Name)
+		assert.Equal("rg1", *ri.ResourceGroupID)
+	}
+	// p2 references another instance, sid2 also has access to another instance, ag2 only to the resource group
+	assert.Equal([]string{"policy:is:image -> kms:k1", "policy:is -> kms", "serviceid:sid1", "apikey:key1", "access-group:ag"}, names)
+	policy, groupPolicy, serviceID, apiKey, accessGroup := ris[1], ris[2], ris[3], ris[4], ris[5]
+	assert.Equal("p1", policy.crn.vpcId)
+	assert.Contains(policy.FormatInstance(true), "authorization roles:Reader")
+	assert.Equal([]*ResourceInstanceWrapper{kms}, policy.waitFor)
+	assert.False(GlobalContext.isProtected(policy))
+
+	// a policy for the whole resource group is listed but not removed
+	assert.Equal("p7", groupPolicy.crn.vpcId)
+	assert.Contains(groupPolicy.FormatInstance(true), "resource-group-only")
+	assert.Empty(groupPolicy.waitFor)
+	assert.True(GlobalContext.isProtected(groupPolicy))
+	assert.Equal([]*ResourceInstanceWrapper{kms, apiKey}, serviceID.waitFor)
+	assert.Equal(serviceID, apiKey.parent)
+	assert.Equal([]*ResourceInstanceWrapper{kms}, accessGroup.waitFor)
+}
@@ -1,22 +1,405 @@
 package iww
 
-import "github.com/rivo/tview"
+// interactive terminal ui: browse the resources in a tree, mark some and remove them
 
-func Interactive(apikey string) error {
-	newPrimitive := func(text string) tview.Primitive {
-		return tview.NewTextView().
-			SetTextAlign(tview.AlignCenter).
-			SetText(text)
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const interactiveHelp = "enter: expand/collapse  space: mark  d: remove marked  q: quit"
+
+type interactive struct {
+	context   *Context
+	app       *tview.Application
+	pages     *tview.Pages
+	tree      *tview.TreeView
+	detail    *tview.TextView
+	status    *tview.TextView
+	nodes     map[*ResourceInstanceWrapper]*tview.TreeNode
+	mutex     sync.Mutex // marked, rmStatus and resources are updated while removing
+	marked    map[*ResourceInstanceWrapper]bool
+	rmStatus  map[*ResourceInstanceWrapper]string
+	resources map[*ResourceInstanceWrapper]string // json of the resources being removed, see remove
+	removing  bool
+}
+
+// Interactive lists the resources that match the parameters, same as ls, and then displays them in a terminal ui
+func Interactive(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, vpcid string) error {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, vpcid, false); err != nil {
+		return err
 	}
+	wrappedResourceInstances, err := List(false)
+	if err != nil {
+		return err
+	}
+	existing := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range wrappedResourceInstances {
+		if _, ok := ri.operations.(UnimplementedServiceOperations); ok || ri.state == SIStateDeleted {
+			continue
+		}
+		existing = append(existing, ri)
+	}
+	return newInteractive(MustGlobalContext(), existing).run()
+}
+
+func newInteractive(context *Context, wrappedResourceInstances []*ResourceInstanceWrapper) *interactive {
+	ui := &interactive{
+		context:   context,
+		app:       tview.NewApplication(),
+		pages:     tview.NewPages(),
+		tree:      tview.NewTreeView(),
+		detail:    tview.NewTextView(),
+		status:    tview.NewTextView(),
+		nodes:     make(map[*ResourceInstanceWrapper]*tview.TreeNode),
+		marked:    make(map[*ResourceInstanceWrapper]bool),
+		rmStatus:  make(map[*ResourceInstanceWrapper]string),
+		resources: make(map[*ResourceInstanceWrapper]string),
+	}
+	root := resourceTree(context, wrappedResourceInstances, func(id string) string {
+		return context.getResourceGroupName(id, false)
+	})
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if ri, ok := node.GetReference().(*ResourceInstanceWrapper); ok {
+			ui.nodes[ri] = node
+			if context.isProtected(ri) {
+				ui.rmStatus[ri] = "protected"
+			}
+			ui.updateNode(ri)
+		}
+		return true
+	})
+	ui.tree.SetRoot(root).SetCurrentNode(root)
+	ui.tree.SetBorder(true).SetTitle("resources")
+	ui.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+	ui.tree.SetChangedFunc(ui.showDetail)
+	ui.tree.SetInputCapture(ui.treeInput)
+	ui.detail.SetDynamicColors(false).SetWrap(true)
+	ui.detail.SetBorder(true).SetTitle("detail")
+	ui.status.SetText(interactiveHelp)
+	ui.status.SetChangedFunc(func() { ui.app.Draw() })
+	ui.status.SetBorder(true).SetTitle("status")
+
 	grid := tview.NewGrid().
-		SetRows(0, 3).
-		SetColumns(30, 0, 30).
-		SetBorders(true).
-		AddItem(newPrimitive("Bodyxxx"), 0, 0, 1, 3, 0, 0, false).
-		AddItem(newPrimitive("Command"), 1, 0, 1, 3, 0, 0, false)
+		SetRows(0, 8).
+		SetColumns(0, 0).
+		AddItem(ui.tree, 0, 0, 1, 1, 0, 0, true).
+		AddItem(ui.detail, 0, 1, 1, 1, 0, 0, false).
+		AddItem(ui.status, 1, 0, 1, 2, 0, 0, false)
+	ui.pages.AddPage("main", grid, true, true)
+	ui.showDetail(root)
+	return ui
+}
+
+func (ui *interactive) run() error {
+	// the log output and progress bar would scribble on the screen
+	log.SetOutput(ui.status)
+	defer log.SetOutput(os.Stderr)
+	ui.context.progressBarWrapper = newSilentProgressBarWrapper()
+	return ui.app.SetRoot(ui.pages, true).SetFocus(ui.tree).Run()
+}
+
+// resourceTree returns the tree: resource group -> region -> vpc -> resources.  Resources that are not in a
+// vpc are directly in the region
+func resourceTree(context *Context, wrappedResourceInstances []*ResourceInstanceWrapper, resourceGroupName func(id string) string) *tview.TreeNode {
+	vpcNames := make(map[string]string)
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType == "is" && ri.crn.vpcType == "vpc" && ri.Name != nil {
+			vpcNames[ri.crn.vpcId] = *ri.Name
+		}
+	}
+	root := tview.NewTreeNode("account " + context.accountID).SetColor(tcell.ColorRed)
+	children := make(map[*tview.TreeNode]map[string]*tview.TreeNode)
+	child := func(parent *tview.TreeNode, key string, text string) *tview.TreeNode {
+		if children[parent] == nil {
+			children[parent] = make(map[string]*tview.TreeNode)
+		}
+		if node, ok := children[parent][key]; ok {
+			return node
+		}
+		node := tview.NewTreeNode(tview.Escape(text)).SetColor(tcell.ColorGreen).SetExpanded(false)
+		children[parent][key] = node
+		parent.AddChild(node)
+		return node
+	}
+	ris := append(RIWs{}, wrappedResourceInstances...)
+	sort.Sort(ris)
+	for _, ri := range ris {
+		resourceGroupID := ""
+		if ri.ResourceGroupID != nil {
+			resourceGroupID = *ri.ResourceGroupID
+		}
+		parent := child(root, resourceGroupID, "group "+firstNonEmpty(resourceGroupName(resourceGroupID), resourceGroupID))
+		parent = child(parent, ri.crn.region, "region "+ri.crn.region)
+		if vpcid := vpcidOf(ri); vpcid != "" {
+			parent = child(parent, vpcid, "vpc "+firstNonEmpty(vpcNames[vpcid], vpcid))
+		}
+		parent.AddChild(tview.NewTreeNode("").SetReference(ri))
+	}
+	sortTree(root)
+	return root
+}
+
+// sortTree sorts the group, region and vpc nodes by text, the resources are already sorted by crn
+func sortTree(node *tview.TreeNode) {
+	nodes := node.GetChildren()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		_, iResource := nodes[i].GetReference().(*ResourceInstanceWrapper)
+		_, jResource := nodes[j].GetReference().(*ResourceInstanceWrapper)
+		if iResource || jResource {
+			return !iResource && jResource
+		}
+		return nodes[i].GetText() < nodes[j].GetText()
+	})
+	for _, child := range nodes {
+		sortTree(child)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func resourceNodeText(ri *ResourceInstanceWrapper, marked bool, rmStatus string) string {
+	mark := "[ ] "
+	if marked {
+		mark = "[x] "
+	}
+	name := ""
+	if ri.Name != nil {
+		name = *ri.Name
+	}
+	text := mark + strings.TrimSpace(ri.crn.resourceType+" "+ri.crn.vpcType) + " " + name
+	if rmStatus != "" {
+		text += " -- " + rmStatus
+	}
+	return tview.Escape(text)
+}
+
+func (ui *interactive) updateNode(ri *ResourceInstanceWrapper) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+	node := ui.nodes[ri]
+	node.SetText(resourceNodeText(ri, ui.marked[ri], ui.rmStatus[ri]))
+	switch {
+	case ui.rmStatus[ri] == RmStatusDeleted:
+		node.SetColor(tcell.ColorGray)
+	case ui.marked[ri]:
+		node.SetColor(tcell.ColorYellow)
+	default:
+		node.SetColor(tcell.ColorWhite)
+	}
+}
 
-	if err := tview.NewApplication().SetRoot(grid, true).SetFocus(grid).Run(); err != nil {
-		panic(err)
+// resources in and below the node
+func nodeResources(node *tview.TreeNode) []*ResourceInstanceWrapper {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	node.Walk(func(node, parent *tview.TreeNode) bool {
+		if ri, ok := node.GetReference().(*ResourceInstanceWrapper); ok {
+			ret = append(ret, ri)
+		}
+		return true
+	})
+	return ret
+}
+
+// toggleMark marks all of the resources in and below the node, or unmarks them if they are already all marked
+func (ui *interactive) toggleMark(node *tview.TreeNode) {
+	ris := make([]*ResourceInstanceWrapper, 0)
+	allMarked := true
+	ui.mutex.Lock()
+	for _, ri := range nodeResources(node) {
+		if ui.rmStatus[ri] != "" {
+			continue // protected or already removed
+		}
+		ris = append(ris, ri)
+		allMarked = allMarked && ui.marked[ri]
+	}
+	for _, ri := range ris {
+		ui.marked[ri] = !allMarked
+	}
+	ui.mutex.Unlock()
+	for _, ri := range ris {
+		ui.updateNode(ri)
+	}
+	ui.showDetail(node)
+}
+
+func (ui *interactive) markedResources() []*ResourceInstanceWrapper {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+	ret := make([]*ResourceInstanceWrapper, 0)
+	for ri, marked := range ui.marked {
+		if marked && ui.rmStatus[ri] == "" {
+			ret = append(ret, ri)
+		}
+	}
+	sort.Sort(RIWs(ret))
+	return ret
+}
+
+func (ui *interactive) treeInput(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Rune() == 'q':
+		ui.app.Stop()
+	case event.Rune() == ' ':
+		if node := ui.tree.GetCurrentNode(); node != nil && !ui.removing {
+			ui.toggleMark(node)
+		}
+	case event.Rune() == 'd':
+		ui.confirmRemove()
+	default:
+		return event
 	}
 	return nil
 }
+
+func (ui *interactive) showDetail(node *tview.TreeNode) {
+	ui.detail.Clear()
+	ri, ok := node.GetReference().(*ResourceInstanceWrapper)
+	if !ok {
+		ris := nodeResources(node)
+		marked := 0
+		ui.mutex.Lock()
+		for _, ri := range ris {
+			if ui.marked[ri] {
+				marked++
+			}
+		}
+		ui.mutex.Unlock()
+		fmt.Fprintln(ui.detail, node.GetText())
+		fmt.Fprintln(ui.detail, "resources:", len(ris))
+		fmt.Fprintln(ui.detail, "marked:", marked)
+		return
+	}
+	ui.mutex.Lock()
+	rmStatus := ui.rmStatus[ri]
+	resource, ok := ui.resources[ri]
+	ui.mutex.Unlock()
+	if !ok {
+		resource = resourceJSON(ri)
+	}
+	writeDetail(ui.detail, ui.context, ri, rmStatus, resource)
+	ui.detail.ScrollToBeginning()
+}
+
+// resourceJSON is the resource read from the cloud, empty if it has not been read
+func resourceJSON(ri *ResourceInstanceWrapper) string {
+	if ri.resource == nil {
+		return ""
+	}
+	content, err := json.MarshalIndent(ri.resource, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// writeDetail writes the description of the resource followed by the resource read from the cloud
+func writeDetail(w io.Writer, context *Context, ri *ResourceInstanceWrapper, rmStatus string, resource string) {
+	name := ""
+	if ri.Name != nil {
+		name = *ri.Name
+	}
+	resourceGroupID := ""
+	if ri.ResourceGroupID != nil {
+		resourceGroupID = *ri.ResourceGroupID
+	}
+	fmt.Fprintln(w, "name:", name)
+	fmt.Fprintln(w, "type:", strings.TrimSpace(ri.crn.resourceType+" "+ri.crn.vpcType))
+	fmt.Fprintln(w, "crn:", ri.crn.Crn)
+	fmt.Fprintln(w, "region:", ri.crn.region, ri.crn.zone)
+	fmt.Fprintln(w, "resource group:", context.getResourceGroupName(resourceGroupID, true), resourceGroupID)
+	if vpcid := vpcidOf(ri); vpcid != "" {
+		fmt.Fprintln(w, "vpc:", vpcid)
+	}
+	if rmStatus != "" {
+		fmt.Fprintln(w, "status:", rmStatus)
+	}
+	if resource != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, resource)
+	}
+}
+
+func (ui *interactive) confirmRemove() {
+	if ui.removing {
+		return
+	}
+	ris := ui.markedResources()
+	if len(ris) == 0 {
+		ui.status.SetText("nothing marked, " + interactiveHelp)
+		return
+	}
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Remove %d marked resources?", len(ris))).
+		AddButtons([]string{"Remove", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("confirm")
+			ui.app.SetFocus(ui.tree)
+			if buttonLabel == "Remove" {
+				ui.removing = true
+				ui.mutex.Lock()
+				for _, ri := range ris {
+					ui.resources[ri] = resourceJSON(ri)
+				}
+				ui.mutex.Unlock()
+				go ui.remove(ris)
+			}
+		})
+	ui.pages.AddPage("confirm", modal, false, true)
+	ui.app.SetFocus(modal)
+}
+
+// remove the resources, run in a go routine, the tree shows the status of each resource.  The wrappers are only read
+// by this go routine while removing, the ui reads the status and resource json reported
+func (ui *interactive) remove(ris []*ResourceInstanceWrapper) {
+	report := func(status string, ri *ResourceInstanceWrapper) {
+		resource := resourceJSON(ri)
+		ui.mutex.Lock()
+		ui.rmStatus[ri] = status
+		ui.resources[ri] = resource
+		ui.mutex.Unlock()
+		ui.app.QueueUpdateDraw(func() { ui.updateNode(ri) })
+	}
+	err := rmServiceInstances(ris, report)
+	for _, ri := range ris {
+		ui.mutex.Lock()
+		rmStatus := ui.rmStatus[ri]
+		ui.mutex.Unlock()
+		if rmStatus == RmStatusDeleted {
+			continue
+		}
+		// resources that are no longer listed are not reported as deleted
+		ri.Fetch()
+		if ri.state == SIStateDeleted || err == nil {
+			report(RmStatusDeleted, ri)
+		} else {
+			report("not deleted", ri)
+		}
+	}
+	ui.app.QueueUpdateDraw(func() {
+		ui.removing = false
+		if err != nil {
+			fmt.Fprintln(ui.status, "remove failed:", err)
+		} else {
+			fmt.Fprintln(ui.status, "remove complete")
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package iww
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInteractiveResourceTree(t *testing.T) {
+	assert := assert.New(t)
+	group := "g1"
+	vpcName, subnetName, keyName := "vpc1", "subnet1", "key1"
+	vpc := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::vpc:r006-v"), &group, &vpcName)
+	subnet := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::subnet:0717-s"), &group, &subnetName)
+	subnet.operations = &VpcGenericOperation{name: subnetName, vpcid: "r006-v"}
+	key := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:kms:us-south:a/111:k1::"), &group, &keyName)
+	context := &Context{accountID: "111"}
+
+	root := resourceTree(context, []*ResourceInstanceWrapper{subnet, key, vpc}, func(id string) string { return "default" })
+	texts := make([]string, 0)
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		texts = append(texts, node.GetText())
+		return true
+	})
+	// the resource node text is set by the ui
+	assert.Equal([]string{"account 111", "group default", "region us-south", "vpc vpc1", "", "", ""}, texts)
+	assert.Equal([]*ResourceInstanceWrapper{subnet, vpc, key}, nodeResources(root)) // sorted by crn
+	assert.Equal("[x[] is subnet subnet1 -- deleted", resourceNodeText(subnet, true, RmStatusDeleted))
+}
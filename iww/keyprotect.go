@@ -10,31 +10,23 @@ import (
 type ResourceFinderKeyProtect struct{}
 
 // --- Find does does not find new resources, it does introduce a new destroy operation
+// The key protect client does not use an authenticator, the bearer token from the global authenticator is
+// put into the context.  The authenticator refreshes the token so get a new client for each operation
 func getKeyProtectClient(crn *Crn) (*kp.Client, context.Context, error) {
 	gc := MustGlobalContext()
-	ctx := context.Background()
-	region := crn.region
-	if gc.token != "" {
-		config := kp.ClientConfig{
-			BaseURL:    ApiEndpoint("https://<region>.kms.cloud.ibm.com", region),
-			TokenURL:   kp.DefaultTokenURL,
-			InstanceID: crn.id,
-			Verbose:    kp.VerboseFailOnly,
-		}
-		client, err := kp.New(config, kp.DefaultTransport())
-		ctx = kp.NewContextWithAuth(ctx, "bearer "+gc.token)
-		return client, ctx, err
-	} else {
-		config := kp.ClientConfig{
-			BaseURL:    ApiEndpoint("https://<region>.kms.cloud.ibm.com", region),
-			APIKey:     gc.apikey,
-			TokenURL:   kp.DefaultTokenURL,
-			InstanceID: crn.id,
-			Verbose:    kp.VerboseFailOnly,
-		}
-		client, err := kp.New(config, kp.DefaultTransport())
-		return client, ctx, err
+	token, err := bearerToken(gc.authenticator)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := kp.ClientConfig{
+		BaseURL:    gc.endpoint("kms", "https://<region>.kms.cloud.ibm.com", crn.region),
+		TokenURL:   kp.DefaultTokenURL,
+		InstanceID: crn.id,
+		Verbose:    kp.VerboseFailOnly,
 	}
+	client, err := kp.New(config, kp.DefaultTransport())
+	ctx := kp.NewContextWithAuth(context.Background(), "bearer "+token)
+	return client, ctx, err
 }
 
 func (finder ResourceFinderKeyProtect) Find(wrappedResourceInstances []*ResourceInstanceWrapper) (moreInstanceWrappers []*ResourceInstanceWrapper, err error) {
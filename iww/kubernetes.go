@@ -0,0 +1,286 @@
+package iww
+
+// kubernetes and openshift clusters.  The resource controller lists the clusters as containers-kubernetes, the
+// finder reads them from the kubernetes service api and replaces their operations: a cluster is deleted with
+// deleteResources=true so the load balancers, security groups and endpoint gateways it created in the vpc go with it.
+// Worker pools are sub instances deleted with the cluster.  The vpc resources in the vpc of a cluster wait for
+// the cluster to be deleted, see ResourceInstanceWrapper.waitFor
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+const kubernetesResourceType = "containers-kubernetes"
+
+// cluster states, see https://cloud.ibm.com/docs/containers?topic=containers-cluster-states-reference
+const (
+	kubernetesStateDeleting = "deleting"
+	kubernetesStateDeleted  = "deleted"
+)
+
+type kubernetesCluster struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	State string   `json:"state"`
+	Type  string   `json:"type"` // kubernetes or openshift
+	Vpcs  []string `json:"vpcs"`
+}
+
+type kubernetesWorkerPool struct {
+	ID          string `json:"id"`
+	PoolName    string `json:"poolName"`
+	Flavor      string `json:"flavor"`
+	WorkerCount int    `json:"workerCount"`
+}
+
+// kubernetesClient is the part of the kubernetes service api used by iww, there is no go sdk
+type kubernetesClient struct {
+	service *core.BaseService
+}
+
+func (context *Context) getKubernetesClient() (*kubernetesClient, error) {
+	service, err := core.NewBaseService(&core.ServiceOptions{
+		Authenticator: context.authenticator,
+		URL:           context.endpoint("containers", "https://containers.cloud.ibm.com/global", ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &kubernetesClient{service: service}, nil
+}
+
+func (client *kubernetesClient) request(method string, path string, query map[string]string, result interface{}) (*core.DetailedResponse, error) {
+	builder := core.NewRequestBuilder(method)
+	if _, err := builder.ResolveRequestURL(client.service.GetServiceURL(), path, nil); err != nil {
+		return nil, err
+	}
+	builder.AddHeader("Accept", "application/json")
+	for key, value := range query {
+		builder.AddQuery(key, value)
+	}
+	request, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return client.service.Request(request, result)
+}
+
+// vpcClusters returns the clusters on vpc infrastructure in all regions
+func (client *kubernetesClient) vpcClusters() ([]kubernetesCluster, error) {
+	clusters := make([]kubernetesCluster, 0)
+	_, err := client.request(core.GET, "/v2/vpc/getClusters", map[string]string{"provider": "vpc-gen2"}, &clusters)
+	return clusters, err
+}
+
+func (client *kubernetesClient) cluster(id string) (*kubernetesCluster, *core.DetailedResponse, error) {
+	cluster := &kubernetesCluster{}
+	response, err := client.request(core.GET, "/v2/getCluster", map[string]string{"cluster": id}, cluster)
+	return cluster, response, err
+}
+
+func (client *kubernetesClient) workerPools(clusterID string) ([]kubernetesWorkerPool, *core.DetailedResponse, error) {
+	workerPools := make([]kubernetesWorkerPool, 0)
+	response, err := client.request(core.GET, "/v2/getWorkerPools", map[string]string{"cluster": clusterID}, &workerPools)
+	return workerPools, response, err
+}
+
+func (client *kubernetesClient) deleteCluster(id string) (*core.DetailedResponse, error) {
+	return client.request(core.DELETE, "/v1/clusters/"+id, map[string]string{"deleteResources": "true"}, nil)
+}
+
+// --------------------------------------
+type ResourceFinderKubernetes struct{}
+
+// Find replaces the operations of the clusters from the resource controller, adds the worker pools and marks the vpc
+// resources that belong to a cluster.  It must follow the vpc finder
+func (finder ResourceFinderKubernetes) Find(wrappedResourceInstances []*ResourceInstanceWrapper) (moreInstanceWrappers []*ResourceInstanceWrapper, err error) {
+	MustGlobalContext().verboseLogger.Println("find ResourceFinderKubernetes")
+	clusters := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType == kubernetesResourceType {
+			clusters = append(clusters, ri)
+		}
+	}
+	if len(clusters) == 0 {
+		return wrappedResourceInstances, nil
+	}
+	client, err := MustGlobalContext().getKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	vpcClusters, err := client.vpcClusters()
+	if err != nil {
+		// the resource controller operations can still delete the clusters
+		log.Print("ResourceFinderKubernetes, getClusters err:", err)
+		return wrappedResourceInstances, nil
+	}
+	byID := make(map[string]kubernetesCluster)
+	for _, cluster := range vpcClusters {
+		byID[cluster.ID] = cluster
+	}
+	moreInstanceWrappers = wrappedResourceInstances
+	for _, ri := range clusters {
+		operations := &KubernetesClusterOperations{}
+		if cluster, ok := byID[ri.crn.id]; ok {
+			operations.cluster = cluster
+		}
+		ri.operations = operations
+		workerPools, _, err := client.workerPools(ri.crn.id)
+		if err != nil {
+			log.Print("ResourceFinderKubernetes, getWorkerPools cluster:", ri.crn.id, " err:", err)
+			continue
+		}
+		for _, workerPool := range workerPools {
+			name := workerPool.PoolName
+			moreInstanceWrappers = append(moreInstanceWrappers, NewSubInstance(ri, "workerpool", workerPool.ID, &name, &KubernetesWorkerPoolOperations{workerPool: workerPool}))
+		}
+	}
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType == "is" {
+			kubernetesClusterResources(ri, clusters)
+		}
+	}
+	return moreInstanceWrappers, nil
+}
+
+// kubernetesClusterOwned is true if the name of the vpc resource starts with the cluster id: load balancers
+// (kube-ID-...), security groups (kube-ID) and endpoint gateways (iks-ID)
+func kubernetesClusterOwned(name string, clusterID string) bool {
+	if clusterID == "" {
+		return false
+	}
+	for _, prefix := range []string{"kube-", "iks-"} {
+		if name == prefix+clusterID || strings.HasPrefix(name, prefix+clusterID+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// kubernetesClusterResources sets the owner of a vpc resource created by a cluster, see kubernetesClusterOwned.
+// Resources owned by a cluster or in the vpc of a cluster wait for the cluster
+func kubernetesClusterResources(ri *ResourceInstanceWrapper, clusters []*ResourceInstanceWrapper) {
+	vpcid := vpcidOf(ri)
+	for _, cluster := range clusters {
+		owned := ri.Name != nil && kubernetesClusterOwned(*ri.Name, cluster.crn.id)
+		if owned {
+			ri.owner = cluster
+		}
+		inVpc := false
+		if operations, ok := cluster.operations.(*KubernetesClusterOperations); ok && vpcid != "" {
+			for _, clusterVpc := range operations.cluster.Vpcs {
+				inVpc = inVpc || clusterVpc == vpcid
+			}
+		}
+		if owned || inVpc {
+			ri.waitFor = append(ri.waitFor, cluster)
+		}
+	}
+}
+
+// --------------------------------------
+type KubernetesClusterOperations struct {
+	cluster kubernetesCluster // from the finder, refreshed by Fetch
+}
+
+func (s *KubernetesClusterOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getKubernetesClient()
+	if err != nil {
+		log.Print("KubernetesClusterOperations.Fetch, getKubernetesClient err:", err)
+		return
+	}
+	cluster, response, err := client.cluster(ri.crn.id)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("KubernetesClusterOperations.Fetch, getCluster err:", err)
+		}
+		return
+	}
+	if len(cluster.Vpcs) == 0 {
+		cluster.Vpcs = s.cluster.Vpcs
+	}
+	s.cluster = *cluster
+	ri.resource = cluster
+	switch cluster.State {
+	case kubernetesStateDeleted:
+		ri.state = SIStateDeleted
+	case kubernetesStateDeleting:
+		ri.state = SIStateDestroying
+	default:
+		ri.state = SIStateExists
+	}
+}
+
+func (s *KubernetesClusterOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getKubernetesClient()
+	if err != nil {
+		log.Print("KubernetesClusterOperations.Destroy, getKubernetesClient err:", err)
+		return
+	}
+	response, err := client.deleteCluster(ri.crn.id)
+	if err != nil {
+		statusCode := "not_returned"
+		if response != nil {
+			statusCode = strconv.Itoa(response.StatusCode)
+		}
+		log.Print("KubernetesClusterOperations.Destroy, StatusCode:", statusCode, " Crn:", ri.crn.Crn, " err:", err)
+	}
+}
+
+func (s *KubernetesClusterOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	name := firstNonEmpty(s.cluster.Name, "--")
+	if ri.Name != nil {
+		name = *ri.Name
+	}
+	description := firstNonEmpty(s.cluster.Type, "kubernetes")
+	if len(s.cluster.Vpcs) > 0 {
+		description += " vpc:" + strings.Join(s.cluster.Vpcs, ",")
+	}
+	return FormatInstance(name, description, *ri.crn)
+}
+
+// --------------------------------------
+// worker pools are deleted with the cluster, the last worker pool of a cluster can not be removed
+type KubernetesWorkerPoolOperations struct {
+	workerPool kubernetesWorkerPool
+}
+
+func (s *KubernetesWorkerPoolOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getKubernetesClient()
+	if err != nil {
+		log.Print("KubernetesWorkerPoolOperations.Fetch, getKubernetesClient err:", err)
+		return
+	}
+	workerPools, response, err := client.workerPools(ri.parent.crn.id)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("KubernetesWorkerPoolOperations.Fetch, getWorkerPools err:", err)
+		}
+		return
+	}
+	ri.state = SIStateDeleted
+	for _, workerPool := range workerPools {
+		if workerPool.ID == ri.crn.vpcId {
+			s.workerPool = workerPool
+			ri.resource = &s.workerPool
+			ri.state = SIStateExists
+		}
+	}
+}
+
+func (s *KubernetesWorkerPoolOperations) Destroy(ri *ResourceInstanceWrapper) {
+	MustGlobalContext().verboseLogger.Print("worker pool waiting for cluster delete:", ri.crn.Crn)
+}
+
+func (s *KubernetesWorkerPoolOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	description := "workerpool flavor:" + s.workerPool.Flavor + " workers:" + strconv.Itoa(s.workerPool.WorkerCount)
+	return FormatInstance(firstNonEmpty(s.workerPool.PoolName, "--"), description, *ri.crn)
+}
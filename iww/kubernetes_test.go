@@ -0,0 +1,101 @@
+package iww
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubernetes(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/vpc/getClusters":
+			io.WriteString(w, `[{"id":"c1","name":"iks","state":"normal","type":"kubernetes","vpcs":["v1"]}]`)
+		case "/v2/getWorkerPools":
+			assert.Equal("c1", r.URL.Query().Get("cluster"))
+			io.WriteString(w, `[{"id":"c1-pool","poolName":"default","flavor":"bx2.4x16","workerCount":3}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{
+		verboseLogger: log.New(io.Discard, "", 0),
+		authenticator: &core.NoAuthAuthenticator{},
+		endpoints:     map[string]string{"containers": server.URL},
+	}
+	defer func() { GlobalContext = saved }()
+
+	group := "g"
+	clusterName := "iks"
+	cluster := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:containers-kubernetes:us-south:a/111:c1::"), &group, &clusterName)
+	cluster.operations = &TypicalServiceOperations{}
+	vpcResource := func(vpcType, id, name, vpcid string) *ResourceInstanceWrapper {
+		ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::"+vpcType+":"+id), &group, &name)
+		ri.operations = &VpcGenericOperation{name: name, vpcid: vpcid}
+		return ri
+	}
+	lb := vpcResource("load-balancer", "l1", "kube-c1-abc", "v1")
+	subnet := vpcResource("subnet", "s1", "subnet", "v1")
+	other := vpcResource("subnet", "s2", "other", "v2")
+
+	ris, err := ResourceFinderKubernetes{}.Find([]*ResourceInstanceWrapper{cluster, lb, subnet, other})
+	assert.Nil(err)
+	assert.Len(ris, 5)
+	pool := ris[4]
+	assert.Equal(cluster, pool.parent)
+	assert.Equal("c1-pool", pool.crn.vpcId)
+	assert.Contains(pool.FormatInstance(true), "workerpool flavor:bx2.4x16 workers:3")
+	assert.Contains(cluster.FormatInstance(true), "kubernetes vpc:v1")
+
+	assert.Equal(cluster, lb.owner)
+	assert.Contains(lb.FormatInstance(true), "cluster:iks")
+	assert.Nil(subnet.owner)
+	assert.Equal([]*ResourceInstanceWrapper{cluster}, subnet.waitFor)
+	assert.Empty(other.waitFor)
+
+	// vpc resources wait for a cluster that is removed with them
+	cluster.state = SIStateExists
+	assert.Nil(lb.waiting(map[*ResourceInstanceWrapper]bool{lb: true}))
+	assert.Equal(cluster, lb.waiting(map[*ResourceInstanceWrapper]bool{cluster: true, lb: true}))
+	cluster.state = SIStateDeleted
+	assert.Nil(lb.waiting(map[*ResourceInstanceWrapper]bool{cluster: true, lb: true}))
+}
+
+func TestKubernetesClusterOwned(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(kubernetesClusterOwned("kube-c1", "c1"))
+	assert.True(kubernetesClusterOwned("kube-c1-abc", "c1"))
+	assert.True(kubernetesClusterOwned("iks-c1", "c1"))
+	assert.False(kubernetesClusterOwned("kube-c10", "c1"))
+	assert.False(kubernetesClusterOwned("my-c1-lb", "c1"))
+	assert.False(kubernetesClusterOwned("kube-", ""))
+
+	// the clusters can not be read, the resource controller operations are kept
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{
+		verboseLogger: log.New(io.Discard, "", 0),
+		authenticator: &core.NoAuthAuthenticator{},
+		endpoints:     map[string]string{"containers": server.URL},
+	}
+	defer func() { GlobalContext = saved }()
+	cluster := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:containers-kubernetes:us-south:a/111:c1::"), nil, nil)
+	operations := &TypicalServiceOperations{}
+	cluster.operations = operations
+	ris, err := ResourceFinderKubernetes{}.Find([]*ResourceInstanceWrapper{cluster})
+	assert.Nil(err)
+	assert.Equal([]*ResourceInstanceWrapper{cluster}, ris)
+	assert.Equal(operations, cluster.operations)
+}
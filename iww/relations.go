@@ -0,0 +1,183 @@
+package iww
+
+// relations between resources from the data the finders and fetches already have, used to nest resources
+// in ls --tree
+
+import (
+	"sort"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const (
+	relationContains = "contains" // vpc contains a subnet, subnet contains an instance, host group contains a host
+	relationAttached = "attached" // volume or floating ip attached to an instance, private path gateway to a load balancer
+	relationKey      = "key"      // resource key of a resource instance
+	relationSub      = "sub"      // sub instance of a resource instance, like a dns zone or a key protect key
+	relationCopy     = "copy"     // snapshot copied from a source snapshot, usually in another region
+)
+
+// relation from the parent to the child, the child is nested under the parent in a tree
+type relation struct {
+	kind   string
+	parent *ResourceInstanceWrapper
+	child  *ResourceInstanceWrapper
+}
+
+// resourceRelations returns the relations between the resources.  Only resources in the list are related
+func resourceRelations(wrappedResourceInstances []*ResourceInstanceWrapper) []relation {
+	inList := make(map[*ResourceInstanceWrapper]bool)
+	isByID := make(map[string]*ResourceInstanceWrapper) // vpc resources by id
+	byCRN := make(map[string]*ResourceInstanceWrapper)
+	instanceByNic := make(map[string]*ResourceInstanceWrapper)
+	for _, ri := range wrappedResourceInstances {
+		inList[ri] = true
+		if ri.crn.resourceType == "is" {
+			isByID[ri.crn.vpcId] = ri
+		}
+		byCRN[ri.crn.Crn] = ri
+		if instance, ok := ri.resource.(*vpcv1.Instance); ok {
+			for _, nic := range instance.NetworkInterfaces {
+				if nic.ID != nil {
+					instanceByNic[*nic.ID] = ri
+				}
+			}
+		}
+	}
+
+	ret := make([]relation, 0)
+	add := func(kind string, parent, child *ResourceInstanceWrapper) {
+		if parent != nil && parent != child && inList[parent] {
+			ret = append(ret, relation{kind: kind, parent: parent, child: child})
+		}
+	}
+	for _, ri := range wrappedResourceInstances {
+		if ri.parent != nil {
+			kind := relationSub
+			if _, ok := ri.operations.(*ResourceKeyOperations); ok {
+				kind = relationKey
+			}
+			add(kind, ri.parent, ri)
+			continue
+		}
+		if ri.crn.resourceType != "is" {
+			continue
+		}
+		switch resource := ri.resource.(type) {
+		case *vpcv1.Instance:
+			if resource.PrimaryNetworkInterface != nil && resource.PrimaryNetworkInterface.Subnet != nil {
+				if subnet, ok := isByID[*resource.PrimaryNetworkInterface.Subnet.ID]; ok && inList[subnet] {
+					add(relationContains, subnet, ri)
+					continue
+				}
+			}
+		case *vpcv1.BareMetalServer:
+			if resource.PrimaryNetworkInterface != nil && resource.PrimaryNetworkInterface.Subnet != nil {
+				if subnet, ok := isByID[*resource.PrimaryNetworkInterface.Subnet.ID]; ok && inList[subnet] {
+					add(relationContains, subnet, ri)
+					continue
+				}
+			}
+		case *vpcv1.DedicatedHost:
+			if resource.Group != nil && resource.Group.ID != nil {
+				add(relationContains, isByID[*resource.Group.ID], ri)
+				continue
+			}
+		case *vpcv1.VirtualNetworkInterface:
+			if resource.Subnet != nil && resource.Subnet.ID != nil {
+				if subnet, ok := isByID[*resource.Subnet.ID]; ok && inList[subnet] {
+					add(relationContains, subnet, ri)
+					continue
+				}
+			}
+		case *vpcv1.PrivatePathServiceGateway:
+			if resource.LoadBalancer != nil && resource.LoadBalancer.ID != nil {
+				if loadBalancer, ok := isByID[*resource.LoadBalancer.ID]; ok && inList[loadBalancer] {
+					add(relationAttached, loadBalancer, ri)
+					continue
+				}
+			}
+		case *vpcv1.Snapshot:
+			if sourceCRN := snapshotSourceCRN(ri); sourceCRN != "" {
+				if source, ok := byCRN[sourceCRN]; ok {
+					add(relationCopy, source, ri)
+					continue
+				}
+			}
+			if resource.SnapshotConsistencyGroup != nil && resource.SnapshotConsistencyGroup.ID != nil {
+				if group, ok := isByID[*resource.SnapshotConsistencyGroup.ID]; ok {
+					add(relationContains, group, ri)
+					continue
+				}
+			}
+		case *vpcv1.Volume:
+			if len(resource.VolumeAttachments) > 0 && resource.VolumeAttachments[0].Instance != nil {
+				add(relationAttached, isByID[*resource.VolumeAttachments[0].Instance.ID], ri)
+				continue
+			}
+		case *vpcv1.FloatingIP:
+			if targetID := floatingIPTargetID(resource.Target); targetID != "" {
+				if instance, ok := instanceByNic[targetID]; ok {
+					add(relationAttached, instance, ri)
+				} else {
+					add(relationAttached, isByID[targetID], ri)
+				}
+				continue
+			}
+		}
+		if vpcid := vpcidOf(ri); vpcid != "" && vpcid != ri.crn.vpcId {
+			add(relationContains, isByID[vpcid], ri)
+		}
+	}
+	return ret
+}
+
+func floatingIPTargetID(target vpcv1.FloatingIPTargetIntf) string {
+	var id *string
+	switch target := target.(type) {
+	case *vpcv1.FloatingIPTarget:
+		id = target.ID
+	case *vpcv1.FloatingIPTargetNetworkInterfaceReference:
+		id = target.ID
+	case *vpcv1.FloatingIPTargetPublicGatewayReference:
+		id = target.ID
+	}
+	if id == nil {
+		return ""
+	}
+	return *id
+}
+
+// relationParents returns the parent of each child, the first relation wins
+func relationParents(relations []relation) map[*ResourceInstanceWrapper]*ResourceInstanceWrapper {
+	ret := make(map[*ResourceInstanceWrapper]*ResourceInstanceWrapper)
+	for _, r := range relations {
+		if _, ok := ret[r.child]; !ok {
+			ret[r.child] = r.parent
+		}
+	}
+	// break any cycle by making the first resource visited in the cycle a root
+	for child := range ret {
+		visited := map[*ResourceInstanceWrapper]bool{child: true}
+		for parent := ret[child]; parent != nil; parent = ret[parent] {
+			if visited[parent] {
+				delete(ret, child)
+				break
+			}
+			visited[parent] = true
+		}
+	}
+	return ret
+}
+
+// relationChildren returns the sorted children of each parent
+func relationChildren(parents map[*ResourceInstanceWrapper]*ResourceInstanceWrapper) map[*ResourceInstanceWrapper]RIWs {
+	ret := make(map[*ResourceInstanceWrapper]RIWs)
+	for child, parent := range parents {
+		ret[parent] = append(ret[parent], child)
+	}
+	for _, children := range ret {
+		sort.Sort(children)
+	}
+	return ret
+}
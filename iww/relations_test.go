@@ -0,0 +1,51 @@
+package iww
+
+import (
+	"testing"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelations(t *testing.T) {
+	assert := assert.New(t)
+	group := "g"
+	is := func(vpcType, id string, vpcid string, resource interface{}) *ResourceInstanceWrapper {
+		ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::"+vpcType+":"+id), &group, nil)
+		ri.operations = &VpcGenericOperation{vpcid: vpcid}
+		ri.resource = resource
+		return ri
+	}
+	str := func(s string) *string { return &s }
+	vpc := is("vpc", "v", "", nil)
+	subnet := is("subnet", "s", "v", nil)
+	instance := is("instance", "i", "v", &vpcv1.Instance{
+		PrimaryNetworkInterface: &vpcv1.NetworkInterfaceInstanceContextReference{ID: str("nic"), Subnet: &vpcv1.SubnetReference{ID: str("s")}},
+		NetworkInterfaces:       []vpcv1.NetworkInterfaceInstanceContextReference{{ID: str("nic")}},
+	})
+	volume := is("volume", "vol", "", &vpcv1.Volume{
+		VolumeAttachments: []vpcv1.VolumeAttachmentReferenceVolumeContext{{Instance: &vpcv1.InstanceReference{ID: str("i")}}},
+	})
+	fip := is("floating-ip", "f", "", &vpcv1.FloatingIP{Target: &vpcv1.FloatingIPTargetNetworkInterfaceReference{ID: str("nic")}})
+	dns := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:dns-svcs:global:a/111:d::"), &group, nil)
+	zone := NewSubInstance(dns, "zone", "z", nil, &Dnszone{})
+	key := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:dns-svcs:global:a/111:d:resource-key:k"), &group, nil)
+	key.operations = &ResourceKeyOperations{}
+	key.parent = dns
+	orphan := NewSubInstance(NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:kms:us-south:a/111:k::"), &group, nil), "key", "k1", nil, &KeyProtectKeyOpertions{})
+
+	relations := resourceRelations([]*ResourceInstanceWrapper{vpc, subnet, instance, volume, fip, dns, zone, key, orphan})
+	assert.Equal([]relation{
+		{relationContains, vpc, subnet},
+		{relationContains, subnet, instance},
+		{relationAttached, instance, volume},
+		{relationAttached, instance, fip},
+		{relationSub, dns, zone},
+		{relationKey, dns, key},
+	}, relations)
+
+	parents := relationParents(relations)
+	assert.Equal(subnet, parents[instance])
+	assert.NotContains(parents, vpc)
+	assert.Equal(RIWs{fip, volume}, relationChildren(parents)[instance]) // sorted by crn
+}
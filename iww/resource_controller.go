@@ -14,6 +14,7 @@ import (
 func (context *Context) getResourceControllerClient() (client *resourcecontrollerv2.ResourceControllerV2, err error) {
 	return resourcecontrollerv2.NewResourceControllerV2(&resourcecontrollerv2.ResourceControllerV2Options{
 		Authenticator: context.authenticator,
+		URL:           context.endpoint("resource_controller", resourcecontrollerv2.DefaultServiceURL, ""),
 	})
 }
 
@@ -85,7 +86,7 @@ func readResourceInstances(resourceControllerClient *resourcecontrollerv2.Resour
 		crn := NewCrn(*ri.CRN)
 		si := NewResourceInstanceWrapper(crn, ri.ResourceGroupID, ri.Name)
 		// filter by region
-		if context.region == "" || context.region == crn.region {
+		if context.inRegion(crn.region) {
 			wrappedResourceInstances = append(wrappedResourceInstances, si)
 		}
 	}
@@ -164,23 +165,24 @@ func readResourceKeys(resourceControllerClient *resourcecontrollerv2.ResourceCon
 		fmt.Println(err)
 		return nil, err
 	}
-	justTheseResourcesCrns := make(map[string]bool)
+	justTheseResourcesCrns := make(map[string]*ResourceInstanceWrapper)
 	for _, justThisOne := range justTheseResources {
-		justTheseResourcesCrns[(*justThisOne.crn).Crn] = true
+		justTheseResourcesCrns[(*justThisOne.crn).Crn] = justThisOne
 	}
 
 	wrappedResourceInstances := make([]*ResourceInstanceWrapper, 0)
 	var lastErr error
 	for _, rk := range resourceKeys {
 		crn_s := *rk.CRN
-		if justTheseResourcesCrns[*rk.SourceCRN] {
+		if source, ok := justTheseResourcesCrns[*rk.SourceCRN]; ok {
 			crn := NewCrn(crn_s)
 			si := NewResourceInstanceWrapper(crn, rk.ResourceGroupID, rk.Name)
+			si.parent = source
 			if err != nil {
 				lastErr = err
 				fmt.Println("BAD CRN:", crn_s)
 			} else {
-				if context.region == "" || context.region == crn.region {
+				if context.inRegion(crn.region) {
 					wrappedResourceInstances = append(wrappedResourceInstances, si)
 				}
 			}
@@ -225,6 +227,7 @@ func (s *ResourceKeyOperations) Fetch(si *ResourceInstanceWrapper) {
 		}
 	} else {
 		si.state = SIStateExists
+		si.resource = s.getResult
 		if s.getResult != nil && *s.getResult.State == "removed" {
 			si.state = SIStateDeleted
 		}
@@ -26,7 +26,7 @@ func (finder ResourceFinderSchematics) Find(wrappedResourceInstances []*Resource
 func (context *Context) getSchematicsClient(crn *Crn) (client *schematicsv1.SchematicsV1, err error) {
 	return schematicsv1.NewSchematicsV1(&schematicsv1.SchematicsV1Options{
 		Authenticator: context.authenticator,
-		URL:           ApiEndpoint("https://<region>.schematics.cloud.ibm.com", crn.region),
+		URL:           context.endpoint("schematics", "https://<region>.schematics.cloud.ibm.com", crn.region),
 	})
 }
 
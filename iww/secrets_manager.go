@@ -0,0 +1,275 @@
+package iww
+
+// secrets manager secret groups and secrets are sub instances of the secrets manager instance.  Secrets are deleted
+// first, then their secret groups and then the instance, see ResourceInstanceWrapper.waitFor.  The default secret
+// group can not be deleted and is not listed
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+const secretsManagerResourceType = "secrets-manager"
+
+const secretGroupDefault = "default"
+
+type secretsManagerSecret struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	SecretType       string `json:"secret_type"` // arbitrary, iam_credentials, imported_cert, ...
+	SecretGroupID    string `json:"secret_group_id"`
+	StateDescription string `json:"state_description"`
+	ExpirationDate   string `json:"expiration_date"`
+	NextRotationDate string `json:"next_rotation_date"`
+	LocksTotal       int    `json:"locks_total"`
+	Rotation         *struct {
+		AutoRotate bool `json:"auto_rotate"`
+	} `json:"rotation"`
+}
+
+type secretsManagerSecretGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// secretsManagerClient is the part of the secrets manager api used by iww for one instance
+type secretsManagerClient struct {
+	service *core.BaseService
+}
+
+func (context *Context) getSecretsManagerClient(crn *Crn) (*secretsManagerClient, error) {
+	url := context.endpoint("secrets_manager", "https://<instance>.<region>.secrets-manager.appdomain.cloud", crn.region)
+	url = strings.Replace(url, "<instance>", crn.id, 1)
+	service, err := core.NewBaseService(&core.ServiceOptions{
+		Authenticator: context.authenticator,
+		URL:           url + "/api/v2",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &secretsManagerClient{service: service}, nil
+}
+
+func (client *secretsManagerClient) request(method string, path string, query map[string]string, result interface{}) (*core.DetailedResponse, error) {
+	builder := core.NewRequestBuilder(method)
+	if _, err := builder.ResolveRequestURL(client.service.GetServiceURL(), path, nil); err != nil {
+		return nil, err
+	}
+	builder.AddHeader("Accept", "application/json")
+	for key, value := range query {
+		builder.AddQuery(key, value)
+	}
+	request, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return client.service.Request(request, result)
+}
+
+func (client *secretsManagerClient) secrets() ([]secretsManagerSecret, error) {
+	ret := make([]secretsManagerSecret, 0)
+	pageSize := 200
+	// 100 times through max, avoid infinite loop
+	for i := 0; i < 100; i++ {
+		result := &struct {
+			Secrets []secretsManagerSecret `json:"secrets"`
+		}{}
+		query := map[string]string{"limit": strconv.Itoa(pageSize), "offset": strconv.Itoa(i * pageSize)}
+		if _, err := client.request(core.GET, "/secrets", query, result); err != nil {
+			return nil, err
+		}
+		ret = append(ret, result.Secrets...)
+		if len(result.Secrets) < pageSize {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (client *secretsManagerClient) secret(id string) (*secretsManagerSecret, *core.DetailedResponse, error) {
+	secret := &secretsManagerSecret{}
+	response, err := client.request(core.GET, "/secrets/"+id+"/metadata", nil, secret)
+	return secret, response, err
+}
+
+func (client *secretsManagerClient) secretGroups() ([]secretsManagerSecretGroup, error) {
+	result := &struct {
+		SecretGroups []secretsManagerSecretGroup `json:"secret_groups"`
+	}{}
+	_, err := client.request(core.GET, "/secret_groups", nil, result)
+	return result.SecretGroups, err
+}
+
+// secretExpired is true if the secret has an expiration date in the past
+func secretExpired(secret *secretsManagerSecret, now time.Time) bool {
+	if secret.ExpirationDate == "" {
+		return false
+	}
+	expiration, err := time.Parse(time.RFC3339, secret.ExpirationDate)
+	return err == nil && expiration.Before(now)
+}
+
+// secretExpiredOf is true if the resource is an expired secret
+func secretExpiredOf(ri *ResourceInstanceWrapper) bool {
+	if secretOperations, ok := ri.operations.(*SecretOperations); ok {
+		return secretExpired(&secretOperations.secret, time.Now())
+	}
+	return false
+}
+
+// --------------------------------------
+type ResourceFinderSecretsManager struct{}
+
+// Find adds the secret groups and the secrets of each secrets manager instance
+func (finder ResourceFinderSecretsManager) Find(wrappedResourceInstances []*ResourceInstanceWrapper) (moreInstanceWrappers []*ResourceInstanceWrapper, err error) {
+	MustGlobalContext().verboseLogger.Println("find ResourceFinderSecretsManager")
+	moreInstanceWrappers = wrappedResourceInstances
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType != secretsManagerResourceType {
+			continue
+		}
+		client, err := MustGlobalContext().getSecretsManagerClient(ri.crn)
+		if err != nil {
+			return nil, err
+		}
+		secretGroups, err := client.secretGroups()
+		if err != nil {
+			log.Print("ResourceFinderSecretsManager, list secret groups crn:", ri.crn.Crn, " err:", err)
+			continue
+		}
+		secrets, err := client.secrets()
+		if err != nil {
+			log.Print("ResourceFinderSecretsManager, list secrets crn:", ri.crn.Crn, " err:", err)
+			continue
+		}
+		groupByID := make(map[string]*ResourceInstanceWrapper)
+		for _, secretGroup := range secretGroups {
+			if secretGroup.ID == secretGroupDefault {
+				continue
+			}
+			name := secretGroup.Name
+			groupInstance := NewSubInstance(ri, "secretgroup", secretGroup.ID, &name, &SecretGroupOperations{})
+			groupByID[secretGroup.ID] = groupInstance
+			ri.waitFor = append(ri.waitFor, groupInstance)
+			moreInstanceWrappers = append(moreInstanceWrappers, groupInstance)
+		}
+		for i := range secrets {
+			secret := secrets[i]
+			name := secret.Name
+			secretInstance := NewSubInstance(ri, "secret", secret.ID, &name, &SecretOperations{secret: secret})
+			if groupInstance, ok := groupByID[secret.SecretGroupID]; ok {
+				groupInstance.waitFor = append(groupInstance.waitFor, secretInstance)
+			}
+			ri.waitFor = append(ri.waitFor, secretInstance)
+			moreInstanceWrappers = append(moreInstanceWrappers, secretInstance)
+		}
+	}
+	return moreInstanceWrappers, nil
+}
+
+// --------------------------------------
+type SecretOperations struct {
+	secret secretsManagerSecret // from the finder, refreshed by Fetch
+}
+
+func (s *SecretOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getSecretsManagerClient(ri.parent.crn)
+	if err != nil {
+		log.Print("SecretOperations.Fetch, getSecretsManagerClient err:", err)
+		return
+	}
+	secret, response, err := client.secret(ri.crn.vpcId)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("SecretOperations.Fetch, get secret metadata err:", err)
+		}
+		return
+	}
+	s.secret = *secret
+	ri.resource = &s.secret
+	ri.state = SIStateExists
+}
+
+func (s *SecretOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getSecretsManagerClient(ri.parent.crn)
+	if err != nil {
+		log.Print("SecretOperations.Destroy, getSecretsManagerClient err:", err)
+		return
+	}
+	if s.secret.LocksTotal > 0 {
+		// a locked secret can not be deleted, the locks are held for the services that use the secret
+		MustGlobalContext().verboseLogger.Print("delete secret locks:", s.secret.LocksTotal, " ", ri.crn.Crn)
+		if _, err := client.request(core.DELETE, "/secrets/"+ri.crn.vpcId+"/locks", nil, nil); err != nil {
+			log.Print("SecretOperations.Destroy, delete secret locks err:", err)
+			return
+		}
+	}
+	if _, err := client.request(core.DELETE, "/secrets/"+ri.crn.vpcId, nil, nil); err != nil {
+		log.Print("SecretOperations.Destroy, delete secret err:", err)
+	}
+}
+
+// secretDescription is the type, state, expiration and rotation of the secret
+func secretDescription(secret *secretsManagerSecret, now time.Time) string {
+	ret := "secret " + secret.SecretType
+	if secret.StateDescription != "" {
+		ret += " state:" + secret.StateDescription
+	}
+	if secretExpired(secret, now) {
+		ret += " EXPIRED:" + secret.ExpirationDate
+	} else if secret.ExpirationDate != "" {
+		ret += " expires:" + secret.ExpirationDate
+	}
+	if secret.Rotation != nil && secret.Rotation.AutoRotate {
+		ret += " rotation:" + firstNonEmpty(secret.NextRotationDate, "auto")
+	}
+	return ret
+}
+
+func (s *SecretOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(s.secret.Name, "--"), secretDescription(&s.secret, time.Now()), *ri.crn)
+}
+
+// --------------------------------------
+type SecretGroupOperations struct{}
+
+func (s *SecretGroupOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getSecretsManagerClient(ri.parent.crn)
+	if err != nil {
+		log.Print("SecretGroupOperations.Fetch, getSecretsManagerClient err:", err)
+		return
+	}
+	secretGroup := &secretsManagerSecretGroup{}
+	response, err := client.request(core.GET, "/secret_groups/"+ri.crn.vpcId, nil, secretGroup)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("SecretGroupOperations.Fetch, get secret group err:", err)
+		}
+		return
+	}
+	ri.resource = secretGroup
+	ri.state = SIStateExists
+}
+
+func (s *SecretGroupOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getSecretsManagerClient(ri.parent.crn)
+	if err != nil {
+		log.Print("SecretGroupOperations.Destroy, getSecretsManagerClient err:", err)
+		return
+	}
+	if _, err := client.request(core.DELETE, "/secret_groups/"+ri.crn.vpcId, nil, nil); err != nil {
+		log.Print("SecretGroupOperations.Destroy, delete secret group err:", err)
+	}
+}
+
+func (s *SecretGroupOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(*ri.Name, "secret group", *ri.crn)
+}
@@ -0,0 +1,54 @@
+package iww
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretsManager(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/secret_groups":
+			io.WriteString(w, `{"secret_groups":[{"id":"default","name":"default"},{"id":"g1","name":"app"}]}`)
+		case "/api/v2/secrets":
+			io.WriteString(w, `{"secrets":[
+				{"id":"s1","name":"old","secret_type":"arbitrary","secret_group_id":"g1","state_description":"active","expiration_date":"2020-01-01T00:00:00Z"},
+				{"id":"s2","name":"key","secret_type":"iam_credentials","secret_group_id":"default","state_description":"active","rotation":{"auto_rotate":true},"next_rotation_date":"2030-01-01T00:00:00Z"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{
+		verboseLogger: log.New(io.Discard, "", 0),
+		authenticator: &core.NoAuthAuthenticator{},
+		endpoints:     map[string]string{"secrets_manager": server.URL},
+	}
+	defer func() { GlobalContext = saved }()
+
+	group := "g"
+	name := "sm"
+	instance := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:secrets-manager:us-south:a/111:guid::"), &group, &name)
+	ris, err := ResourceFinderSecretsManager{}.Find([]*ResourceInstanceWrapper{instance})
+	assert.Nil(err)
+	assert.Len(ris, 4)
+	secretGroup, expired, rotated := ris[1], ris[2], ris[3]
+	assert.Equal("g1", secretGroup.crn.vpcId)
+	assert.Equal([]*ResourceInstanceWrapper{secretGroup, expired, rotated}, instance.waitFor)
+	assert.Equal([]*ResourceInstanceWrapper{expired}, secretGroup.waitFor)
+
+	assert.True(secretExpiredOf(expired))
+	assert.False(secretExpiredOf(rotated))
+	assert.Contains(expired.FormatInstance(true), "secret arbitrary state:active EXPIRED:2020-01-01T00:00:00Z")
+	assert.Equal("secret iam_credentials state:active rotation:2030-01-01T00:00:00Z", secretDescription(&rotated.operations.(*SecretOperations).secret, time.Now()))
+}
@@ -5,10 +5,12 @@ package iww
 import (
 	"errors"
 	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/IBM/go-sdk-core/v5/core"
 	"github.com/IBM/vpc-go-sdk/vpcv1"
 	"github.com/Workiva/go-datastructures/set"
 )
@@ -35,10 +37,50 @@ func (finder ResourceFinderVpc) Find(wrappedResourceInstances []*ResourceInstanc
 			}
 		}
 	}
+	// the routing tables and routes find the resources that wait for them, not needed while removing, see vpcWaitFor
+	var crnToWrapper map[string]*ResourceInstanceWrapper
+	if !MustGlobalContext().pruning {
+		crnToWrapper = make(map[string]*ResourceInstanceWrapper)
+		for _, ri := range moreInstanceWrappers {
+			crnToWrapper[ri.crn.Crn] = ri
+		}
+	}
+	// sub instances that are only available from their parent
+	subInstanceListers := []struct {
+		parentVpcType string
+		lister        vpcSubInstanceLister
+	}{
+		{"vpc", func(client *vpcv1.VpcV1, vpc *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+			return listRoutingTables(client, vpc, crnToWrapper)
+		}},
+		{"subnet", listReservedIPs},
+		{"share", listShareMountTargets},
+		{"backup-policy", listBackupPolicyPlans},
+		{"vpn-server", listVPNServerChildren},
+		{"private-path-service-gateway", listPrivatePathBindings},
+	}
+	for _, subInstanceLister := range subInstanceListers {
+		subInstances, err := readVpcSubInstances(moreInstanceWrappers, subInstanceLister.parentVpcType, subInstanceLister.lister)
+		if err != nil {
+			return nil, err
+		}
+		moreInstanceWrappers = append(moreInstanceWrappers, subInstances...)
+	}
+	vpcWaitFor(moreInstanceWrappers)
 	err = nil
 	return
 }
 
+// vpcWaitFor makes the snapshots and images wait for the resources that must be deleted first.  Only the wrappers of
+// the initial list need the waitFor, not the wrappers listed again while removing, see pruneResourcesThatDoNotExist
+func vpcWaitFor(ris []*ResourceInstanceWrapper) {
+	if MustGlobalContext().pruning {
+		return
+	}
+	snapshotWaitFor(ris)
+	imageWaitFor(ris)
+}
+
 // These are irregular operations, notice the switch statements
 type VpcSpecificVPNGatewayInstance struct{}
 
@@ -86,7 +128,7 @@ func (spec VpcSpecificInstanceTemplateInstance) Get(service *vpcv1.VpcV1, id str
 		case *vpcv1.InstanceTemplateInstanceByVolume:
 			name = *instance.Name
 		*/
-		case *vpcv1.InstanceTemplateInstanceByImage:
+		case *vpcv1.InstanceTemplateInstanceByImageInstanceTemplateContext:
 			name = *instance.Name
 		}
 		return name, "", true, response, nil
@@ -123,13 +165,74 @@ var VpcSubtypeOperationsIrregularMap = map[string]VpcSubtypeOperations{
 	"instance-template": VpcSpecificInstanceTemplateInstance{},
 	"vpn":               VpcSpecificVPNGatewayInstance{},
 	"ikepolicy":         VpcSpecificIkePolicy{},
+	"ipsecpolicy":       VpcSpecificIPsecPolicy{},
+}
+
+// vpcidOf returns the id of the vpc that contains the resource, the id of the vpc for a vpc, "" if not in a vpc
+func vpcidOf(ri *ResourceInstanceWrapper) string {
+	if ri.crn.resourceType == "is" && ri.crn.vpcType == "vpc" {
+		return ri.crn.vpcId
+	}
+	if vpcOperations, ok := ri.operations.(VpcResourceInstanceOperations); ok {
+		if vpcid := vpcOperations.Vpcid(); vpcid != "" {
+			return vpcid
+		}
+	}
+	// not fetched yet, the listed resource has the vpc
+	if metadata, ok := vpcMetadata(ri); ok {
+		return metadata.Vpcid
+	}
+	return ""
+}
+
+// vpcMetadata returns the metadata of the last fetched or listed vpc resource, false if not a regular vpc resource
+func vpcMetadata(ri *ResourceInstanceWrapper) (VpcResourceMetadata, bool) {
+	if ri.crn.resourceType != "is" || ri.resource == nil {
+		return VpcResourceMetadata{}, false
+	}
+	if lister, ok := VpcSubtypeOperationsMap[ri.crn.vpcType].(VpcSubtypeListOperations); ok {
+		return lister.Metadata(ri.resource)
+	}
+	return VpcResourceMetadata{}, false
 }
 
 // IS operations
 type VpcGenericOperation struct {
-	operations VpcSubtypeOperations // actual instance like a subnet, security group, acl, ...
-	name       string
-	vpcid      string
+	operations     VpcSubtypeOperations // actual instance like a subnet, security group, acl, ...
+	name           string
+	vpcid          string
+	failedReported bool // the failed status has been logged
+}
+
+// vpcTransitionalStatus are the status and lifecycle_state values of a resource that is being created, changed or
+// deleted.  A delete would fail with a 409 conflict, wait for the resource to settle
+var vpcTransitionalStatus = map[string]bool{
+	"pending":             true,
+	"updating":            true,
+	"waiting":             true,
+	"deleting":            true,
+	"pending_deletion":    true,
+	"create_pending":      true,
+	"update_pending":      true,
+	"delete_pending":      true,
+	"maintenance_pending": true,
+	"migrate_pending":     true,
+}
+
+const vpcStatusFailed = "failed"
+
+// lifecycle moves an existing resource to the destroying state while the status is transitional, it is back to
+// exists once the resource settles or deleted once it is gone
+func (vpc *VpcGenericOperation) lifecycle(ri *ResourceInstanceWrapper, status string) {
+	if vpcTransitionalStatus[status] {
+		MustGlobalContext().verboseLogger.Print("vpc resource status:", status, " ", ri.crn.Crn)
+		ri.state = SIStateDestroying
+		return
+	}
+	if status == vpcStatusFailed && !vpc.failedReported {
+		vpc.failedReported = true
+		log.Print("vpc resource is in the failed state, delete it in the console or open a support case if rm does not remove it: ", vpc.name, " ", ri.crn.Crn)
+	}
 }
 
 type VpcResourceInstanceOperations interface {
@@ -142,10 +245,13 @@ func (vpc *VpcGenericOperation) Fetch(ri *ResourceInstanceWrapper) {
 	if err != nil {
 		log.Print("VpcGenericOperation.Fetch, getVpcClient err:", err)
 	}
-	name, vpcid, found, _, err := vpc.operations.Get(client, ri.crn.vpcId)
+	name, vpcid, found, response, err := vpc.operations.Get(client, ri.crn.vpcId)
 	if found {
 		// when found then name is set and err should be nil
 		ri.state = SIStateExists
+		if detailedResponse, ok := response.(*core.DetailedResponse); ok {
+			ri.resource = detailedResponse.Result
+		}
 		if vpc.name != "" && vpc.name != name {
 			panic("name of vpc resource instance has changed")
 		}
@@ -154,6 +260,9 @@ func (vpc *VpcGenericOperation) Fetch(ri *ResourceInstanceWrapper) {
 		}
 		vpc.name = name
 		vpc.vpcid = vpcid
+		if metadata, ok := vpcMetadata(ri); ok {
+			vpc.lifecycle(ri, metadata.Status)
+		}
 	} else {
 		if err == nil {
 			// when not found and err is nil then the resource was identified as not existing
@@ -190,8 +299,8 @@ func (vpc *VpcGenericOperation) Vpcid() string {
 }
 
 // --------------------------------------
-// Some operations, like security group and acl, do not need to be deleted, deleting the vpc will auto delete them
-// And deleting the default will complain.
+// Some operations, like the default security group and acl, do not need to be deleted, deleting the vpc will auto
+// delete them.  And deleting the default will complain.
 type VpcGenericNoDeleteOperation struct {
 	operations    VpcGenericOperation
 	destoryCalled bool
@@ -285,6 +394,115 @@ func (vpc *VpcGenericInstanceGroupOperation) FormatInstance(ri *ResourceInstance
 	return vpc.operations.FormatInstance(ri, fast)
 }
 
+// --------------------------------------
+// bare metal servers are stopped before they are deleted
+type VpcGenericBareMetalServerOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericBareMetalServerOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericBareMetalServerOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+// bareMetalServerStatusStopping is no longer a documented status but it is still reported while the server stops
+const bareMetalServerStatusStopping = "stopping"
+
+func (vpc *VpcGenericBareMetalServerOperation) Destroy(ri *ResourceInstanceWrapper) {
+	server, ok := ri.resource.(*vpcv1.BareMetalServer)
+	if !ok || server.Status == nil || *server.Status == vpcv1.BareMetalServerStatusStoppedConst || *server.Status == vpcv1.BareMetalServerStatusFailedConst {
+		vpc.operations.Destroy(ri)
+		return
+	}
+	if *server.Status == bareMetalServerStatusStopping {
+		MustGlobalContext().verboseLogger.Print("bare metal server stopping:", ri.crn.Crn)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericBareMetalServerOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	_, err = client.StopBareMetalServer(client.NewStopBareMetalServerOptions(ri.crn.vpcId, vpcv1.StopBareMetalServerOptionsTypeHardConst))
+	if err != nil {
+		log.Print("VpcGenericBareMetalServerOperation.Destroy, StopBareMetalServer err:", err)
+	}
+}
+
+func (vpc *VpcGenericBareMetalServerOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// dedicated hosts are disabled so no new instances are placed and deleted after the instances on the host are gone
+type VpcGenericDedicatedHostOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericDedicatedHostOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericDedicatedHostOperation) Destroy(ri *ResourceInstanceWrapper) {
+	host, ok := ri.resource.(*vpcv1.DedicatedHost)
+	if !ok {
+		vpc.operations.Destroy(ri)
+		return
+	}
+	if host.InstancePlacementEnabled != nil && *host.InstancePlacementEnabled {
+		client, err := MustGlobalContext().getVpcClient(ri.crn)
+		if err != nil {
+			log.Print("VpcGenericDedicatedHostOperation.Destroy, getVpcClient err:", err)
+			return
+		}
+		disabled := false
+		dedicatedHostPatch, err := (&vpcv1.DedicatedHostPatch{InstancePlacementEnabled: &disabled}).AsPatch()
+		if err != nil {
+			log.Print("VpcGenericDedicatedHostOperation.Destroy, AsPatch err:", err)
+			return
+		}
+		_, _, err = client.UpdateDedicatedHost(client.NewUpdateDedicatedHostOptions(ri.crn.vpcId, dedicatedHostPatch))
+		if err != nil {
+			log.Print("VpcGenericDedicatedHostOperation.Destroy, UpdateDedicatedHost err:", err)
+			return
+		}
+	}
+	if len(host.Instances) > 0 {
+		MustGlobalContext().verboseLogger.Print("dedicated host waiting for instances:", len(host.Instances), " ", ri.crn.Crn)
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericDedicatedHostOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// dedicated host groups are deleted after the hosts in the group
+type VpcGenericDedicatedHostGroupOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericDedicatedHostGroupOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericDedicatedHostGroupOperation) Destroy(ri *ResourceInstanceWrapper) {
+	if group, ok := ri.resource.(*vpcv1.DedicatedHostGroup); ok && len(group.DedicatedHosts) > 0 {
+		MustGlobalContext().verboseLogger.Print("dedicated host group waiting for hosts:", len(group.DedicatedHosts), " ", ri.crn.Crn)
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericDedicatedHostGroupOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
 type VpcSpecificVPCInstanceWrapper struct {
 }
 
@@ -309,14 +527,66 @@ func NewVpcOperations(crn *Crn) (ResourceInstanceOperations, error) {
 		}
 		// a few special case wrappers around the standard operations
 		switch crn.vpcType {
-		case "network-acl", "security-group":
-			return &VpcGenericNoDeleteOperation{
+		case "security-group":
+			return &VpcGenericSecurityGroupOperation{
+				noDelete: VpcGenericNoDeleteOperation{operations: *genericOperation},
+			}, nil
+		case "network-acl":
+			return &VpcGenericNetworkACLOperation{
+				noDelete: VpcGenericNoDeleteOperation{operations: *genericOperation},
+			}, nil
+		case "image":
+			return &VpcGenericImageOperation{
+				operations: *genericOperation,
+			}, nil
+		case "instance":
+			return &VpcGenericInstanceOperation{
 				operations: *genericOperation,
 			}, nil
 		case "instance-group":
 			return &VpcGenericInstanceGroupOperation{
 				operations: *genericOperation,
 			}, nil
+		case "subnet":
+			return &VpcGenericSubnetOperation{
+				operations: *genericOperation,
+			}, nil
+		case "share":
+			return &VpcGenericShareOperation{
+				operations: *genericOperation,
+			}, nil
+		case "backup-policy":
+			return &VpcGenericBackupPolicyOperation{
+				operations: *genericOperation,
+			}, nil
+		case "snapshot":
+			return &VpcGenericSnapshotOperation{
+				operations: *genericOperation,
+			}, nil
+		case "snapshot-consistency-group":
+			return &VpcGenericSnapshotConsistencyGroupOperation{
+				operations: *genericOperation,
+			}, nil
+		case "bare-metal-server":
+			return &VpcGenericBareMetalServerOperation{
+				operations: *genericOperation,
+			}, nil
+		case "dedicated-host":
+			return &VpcGenericDedicatedHostOperation{
+				operations: *genericOperation,
+			}, nil
+		case "dedicated-host-group":
+			return &VpcGenericDedicatedHostGroupOperation{
+				operations: *genericOperation,
+			}, nil
+		case "virtual-network-interface":
+			return &VpcGenericVirtualNetworkInterfaceOperation{
+				operations: *genericOperation,
+			}, nil
+		case "private-path-service-gateway":
+			return &VpcGenericPrivatePathServiceGatewayOperation{
+				operations: *genericOperation,
+			}, nil
 		case "vpc":
 			genericOperation.operations = &VpcSpecificVPCInstanceWrapper{}
 			return genericOperation, nil
@@ -324,9 +594,17 @@ func NewVpcOperations(crn *Crn) (ResourceInstanceOperations, error) {
 			return genericOperation, nil
 		}
 	} else if specificInstance, ok := VpcSubtypeOperationsIrregularMap[crn.vpcType]; ok {
-		return &VpcGenericOperation{
+		genericOperation := &VpcGenericOperation{
 			operations: specificInstance,
-		}, nil
+		}
+		switch crn.vpcType {
+		case "ikepolicy", "ipsecpolicy":
+			return &VpcGenericVPNPolicyOperation{
+				operations: *genericOperation,
+			}, nil
+		default:
+			return genericOperation, nil
+		}
 	} else {
 		return UnimplementedServiceOperations{}, nil
 	}
@@ -375,6 +653,94 @@ func listIkePolicies(list *set.Set, client *vpcv1.VpcV1, wg *sync.WaitGroup) {
 	}
 }
 
+// listVpcResources appends onto list the resources of each regular vpc type, resources already known by crn are skipped
+func listVpcResources(list *set.Set, client *vpcv1.VpcV1, known map[string]bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+	context := MustGlobalContext()
+	for _, vpcType := range vpcSubtypes() {
+		lister, ok := VpcSubtypeOperationsMap[vpcType].(VpcSubtypeListOperations)
+		if !ok {
+			continue
+		}
+		metadatas, err := lister.List(client, context.resourceGroupID)
+		if err != nil {
+			// the resource controller may still have them, keep going with the other types
+			log.Print("listVpcResources, List ", vpcType, " err:", err)
+			continue
+		}
+		for _, metadata := range metadatas {
+			if metadata.CRN == "" || known[metadata.CRN] || vpcDeletedWithTarget(metadata.Resource) {
+				continue
+			}
+			crn := NewCrn(metadata.CRN)
+			if context.accountID != "" && crn.account() != context.accountID {
+				continue
+			}
+			resourceGroupID, name := metadata.ResourceGroupID, metadata.Name
+			ri := NewResourceInstanceWrapper(crn, &resourceGroupID, &name)
+			ri.resource = metadata.Resource
+			list.Add(&resourceInstanceWrapperErr{ri, nil})
+		}
+	}
+}
+
+// vpcSubtypes are the regular vpc types sorted so the listing order is stable
+func vpcSubtypes() []string {
+	ret := make([]string, 0, len(VpcSubtypeOperationsMap))
+	for vpcType := range VpcSubtypeOperationsMap {
+		ret = append(ret, vpcType)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// vpcSubInstanceLister lists the sub instances of a parent vpc resource, like the reserved ips of a subnet
+type vpcSubInstanceLister func(client *vpcv1.VpcV1, parent *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error)
+
+// readVpcSubInstances returns the sub instances of each parent of the vpc type
+func readVpcSubInstances(wrappedResourceInstances []*ResourceInstanceWrapper, parentVpcType string, lister vpcSubInstanceLister) ([]*ResourceInstanceWrapper, error) {
+	set := set.New()
+	var wg sync.WaitGroup
+	list := func(parent *ResourceInstanceWrapper) {
+		defer wg.Done()
+		client, err := MustGlobalContext().getVpcClient(parent.crn)
+		if err != nil {
+			set.Add(&resourceInstanceWrapperErr{nil, err})
+			return
+		}
+		subInstances, err := lister(client, parent)
+		if err != nil {
+			// a parent that was just deleted should not fail the list
+			MustGlobalContext().verboseLogger.Print("readVpcSubInstances ", parentVpcType, " err:", err)
+			return
+		}
+		for _, ri := range subInstances {
+			set.Add(&resourceInstanceWrapperErr{ri, nil})
+		}
+	}
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType != "is" || ri.crn.vpcType != parentVpcType {
+			continue
+		}
+		wg.Add(1)
+		if Async {
+			go list(ri)
+		} else {
+			list(ri)
+		}
+	}
+	wg.Wait()
+	ret := make([]*ResourceInstanceWrapper, 0)
+	for _, _rie := range set.Flatten() {
+		rie := _rie.(*resourceInstanceWrapperErr)
+		if rie.err != nil {
+			return nil, rie.err
+		}
+		ret = append(ret, rie.ri)
+	}
+	return ret, nil
+}
+
 var check bool = false
 
 func regionNames(context *Context) (map[string]string, error) {
@@ -418,6 +784,9 @@ func vpcRegionClients() ([]*vpcv1.VpcV1, error) {
 	}
 	regionClients := make([]*vpcv1.VpcV1, 0)
 	for _, region := range regions {
+		if !context.inRegion(region) {
+			continue
+		}
 		client, err := context.getVpcClientFromRegion(region)
 		/* write a bug report region.Endpoint is https://au-syd.iaas.cloud.ibm.com expecting https://au-syd.iaas.cloud.ibm.com/v1
 		client, err = vpcv1.NewVpcV1(&vpcv1.VpcV1Options{
@@ -442,17 +811,26 @@ func readVpcExtraInstances(currentResourceInstances []*ResourceInstanceWrapper)
 	set := set.New()
 	var wg sync.WaitGroup
 
+	// vpc resources are usually in the resource controller as well, only add the missing ones
+	known := make(map[string]bool)
+	for _, ri := range currentResourceInstances {
+		known[ri.crn.Crn] = true
+	}
 	for _, client := range regionClients {
 		time.Sleep(10 * time.Millisecond) // avoid rate limiting
-		wg.Add(2)
+		wg.Add(4)
 		if Async {
 			// go listInstanceTemplates(list, client, &wg)
 			go listInstanceTemplates(set, client, &wg)
 			go listIkePolicies(set, client, &wg)
+			go listIPsecPolicies(set, client, &wg)
+			go listVpcResources(set, client, known, &wg)
 		} else {
 			// listInstanceTemplates(list, client, &wg)
 			listInstanceTemplates(set, client, &wg)
 			listIkePolicies(set, client, &wg)
+			listIPsecPolicies(set, client, &wg)
+			listVpcResources(set, client, known, &wg)
 		}
 
 	}
@@ -461,7 +839,7 @@ func readVpcExtraInstances(currentResourceInstances []*ResourceInstanceWrapper)
 	for _, _rie := range set.Flatten() {
 		rie := _rie.(*resourceInstanceWrapperErr)
 		if rie.err != nil {
-			return nil, err
+			return nil, rie.err
 		}
 		wrappedResourceInstances = append(wrappedResourceInstances, rie.ri)
 	}
@@ -0,0 +1,205 @@
+package iww
+
+// backup policies, their plans and the snapshots the plans create.  Plans do not have a crn.  The fake crn has the
+// backup policy id in the id and the plan id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:POLICYID:backup-policy-plan:PLANID
+
+import (
+	"log"
+	"strings"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const vpcTypeBackupPolicyPlan = "backup-policy-plan"
+
+type VpcBackupPolicyPlanOperations struct {
+	name string
+}
+
+func (plan *VpcBackupPolicyPlanOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcBackupPolicyPlanOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	backupPolicyPlan, response, err := client.GetBackupPolicyPlan(client.NewGetBackupPolicyPlanOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcBackupPolicyPlanOperations.Fetch, GetBackupPolicyPlan err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = backupPolicyPlan
+	plan.name = vpcString(backupPolicyPlan.Name)
+}
+
+func (plan *VpcBackupPolicyPlanOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcBackupPolicyPlanOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	if _, _, err = client.DeleteBackupPolicyPlan(client.NewDeleteBackupPolicyPlanOptions(ri.crn.id, ri.crn.vpcId)); err != nil {
+		log.Print("VpcBackupPolicyPlanOperations.Destroy, DeleteBackupPolicyPlan err:", err)
+	}
+}
+
+func (plan *VpcBackupPolicyPlanOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(plan.name, "--"), "vpc", *ri.crn)
+}
+
+// listBackupPolicyPlans returns the plans of the backup policy
+func listBackupPolicyPlans(client *vpcv1.VpcV1, policy *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	collection, _, err := client.ListBackupPolicyPlans(client.NewListBackupPolicyPlansOptions(policy.crn.vpcId))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ResourceInstanceWrapper, 0, len(collection.Plans))
+	for i := range collection.Plans {
+		plan := &collection.Plans[i]
+		crn := NewFakeCrn("is", policy.crn.vpcId, vpcTypeBackupPolicyPlan, *plan.ID, policy.crn.region)
+		ri := NewResourceInstanceWrapper(crn, policy.ResourceGroupID, plan.Name)
+		ri.operations = &VpcBackupPolicyPlanOperations{name: vpcString(plan.Name)}
+		ri.resource = plan
+		ri.parent = policy
+		ret = append(ret, ri)
+	}
+	return ret, nil
+}
+
+// --------------------------------------
+// backup policies are deleted after their plans so no more snapshots are created
+type VpcGenericBackupPolicyOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericBackupPolicyOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericBackupPolicyOperation) Destroy(ri *ResourceInstanceWrapper) {
+	policy, ok := ri.resource.(*vpcv1.BackupPolicy)
+	if !ok || len(policy.Plans) == 0 {
+		vpc.operations.Destroy(ri)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericBackupPolicyOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	for _, plan := range policy.Plans {
+		if _, _, err := client.DeleteBackupPolicyPlan(client.NewDeleteBackupPolicyPlanOptions(*policy.ID, *plan.ID)); err != nil {
+			log.Print("VpcGenericBackupPolicyOperation.Destroy, DeleteBackupPolicyPlan err:", err)
+		}
+	}
+}
+
+func (vpc *VpcGenericBackupPolicyOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// snapshotBackupPolicyPlan returns the backup policy plan that created the snapshot, nil if not created by a plan
+func snapshotBackupPolicyPlan(ri *ResourceInstanceWrapper) *vpcv1.BackupPolicyPlanReference {
+	switch resource := ri.resource.(type) {
+	case *vpcv1.Snapshot:
+		return resource.BackupPolicyPlan
+	case *vpcv1.SnapshotConsistencyGroup:
+		return resource.BackupPolicyPlan
+	}
+	return nil
+}
+
+// backupPolicyIDFromHref returns the policy id in a plan href: https://us-south.iaas.cloud.ibm.com/v1/backup_policies/POLICYID/plans/PLANID
+func backupPolicyIDFromHref(href string) string {
+	parts := strings.Split(href, "/backup_policies/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.Split(parts[1], "/")[0]
+}
+
+// snapshotWaitFor makes the snapshots and consistency groups created by a backup policy plan wait for the backup
+// policy, otherwise the policy would keep creating them, and the snapshots in a consistency group wait for the group,
+// the group deletes its snapshots.  Only the backup policies and groups being removed are waited for, see
+// ResourceInstanceWrapper.waiting
+func snapshotWaitFor(ris []*ResourceInstanceWrapper) {
+	byID := make(map[string]*ResourceInstanceWrapper)
+	regions := make(map[string]bool)
+	waiters := false
+	for _, ri := range ris {
+		if ri.crn.resourceType != "is" {
+			continue
+		}
+		switch ri.crn.vpcType {
+		case "backup-policy", "snapshot-consistency-group":
+			byID[ri.crn.vpcId] = ri
+			regions[ri.crn.region] = true
+		case "snapshot":
+			byID[ri.crn.vpcId] = ri
+			waiters = true
+		}
+	}
+	if !waiters || len(regions) == 0 {
+		return
+	}
+	waitFor := func(id string, plan *vpcv1.BackupPolicyPlanReference, group *vpcv1.SnapshotConsistencyGroupReference) {
+		ri, ok := byID[id]
+		if !ok {
+			return
+		}
+		if plan != nil && plan.Href != nil {
+			if policy, ok := byID[backupPolicyIDFromHref(*plan.Href)]; ok {
+				ri.waitFor = append(ri.waitFor, policy)
+			}
+		}
+		if group != nil && group.ID != nil {
+			if group, ok := byID[*group.ID]; ok {
+				ri.waitFor = append(ri.waitFor, group)
+			}
+		}
+	}
+	for region := range regions {
+		client, err := MustGlobalContext().getVpcClientFromRegion(region)
+		if err != nil {
+			log.Print("snapshotWaitFor, getVpcClient err:", err)
+			continue
+		}
+		snapshotOptions := client.NewListSnapshotsOptions()
+		for {
+			collection, _, err := client.ListSnapshots(snapshotOptions)
+			if err != nil {
+				log.Print("snapshotWaitFor, ListSnapshots region:", region, " err:", err)
+				break
+			}
+			for _, snapshot := range collection.Snapshots {
+				waitFor(vpcString(snapshot.ID), snapshot.BackupPolicyPlan, snapshot.SnapshotConsistencyGroup)
+			}
+			start, err := collection.GetNextStart()
+			if err != nil || start == nil {
+				break
+			}
+			snapshotOptions.SetStart(*start)
+		}
+		groupOptions := client.NewListSnapshotConsistencyGroupsOptions()
+		for {
+			collection, _, err := client.ListSnapshotConsistencyGroups(groupOptions)
+			if err != nil {
+				log.Print("snapshotWaitFor, ListSnapshotConsistencyGroups region:", region, " err:", err)
+				break
+			}
+			for _, group := range collection.SnapshotConsistencyGroups {
+				waitFor(vpcString(group.ID), group.BackupPolicyPlan, nil)
+			}
+			start, err := collection.GetNextStart()
+			if err != nil || start == nil {
+				break
+			}
+			groupOptions.SetStart(*start)
+		}
+	}
+}
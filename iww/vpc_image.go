@@ -0,0 +1,221 @@
+package iww
+
+// private images.  An image is deleted after the instances, instance templates and snapshots that reference it, see
+// imageWaitFor.  Instead of deleting, images can be retired: deprecated or obsoleted, see ImageRetire
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+type VpcGenericImageOperation struct {
+	operations VpcGenericOperation
+	users      []string // resources that reference the image when it was listed, like instance:name, see imageWaitFor
+}
+
+func (vpc *VpcGenericImageOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+// imageUser is an instance, instance template or snapshot that references an image
+type imageUser struct {
+	imageID string
+	crn     string
+	label   string // like instance:name
+}
+
+// listImageUsers returns the instances, instance templates and snapshots in the region that reference an image
+func listImageUsers(client *vpcv1.VpcV1) ([]imageUser, error) {
+	ret := make([]imageUser, 0)
+	add := func(id *string, crn *string, label string) {
+		if id != nil {
+			ret = append(ret, imageUser{imageID: *id, crn: vpcString(crn), label: label})
+		}
+	}
+	instanceOptions := client.NewListInstancesOptions()
+	for {
+		collection, _, err := client.ListInstances(instanceOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range collection.Instances {
+			if instance.Image != nil {
+				add(instance.Image.ID, instance.CRN, "instance:"+vpcString(instance.Name))
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			break
+		}
+		instanceOptions.SetStart(*start)
+	}
+	templateOptions := client.NewListInstanceTemplatesOptions()
+	templates, _, err := client.ListInstanceTemplates(templateOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates.Templates {
+		if template, ok := t.(*vpcv1.InstanceTemplate); ok {
+			if image, ok := template.Image.(*vpcv1.ImageIdentity); ok {
+				add(image.ID, template.CRN, "instance-template:"+vpcString(template.Name))
+			}
+		}
+	}
+	snapshotOptions := client.NewListSnapshotsOptions()
+	for {
+		collection, _, err := client.ListSnapshots(snapshotOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, snapshot := range collection.Snapshots {
+			if snapshot.SourceImage != nil {
+				add(snapshot.SourceImage.ID, snapshot.CRN, "snapshot:"+vpcString(snapshot.Name))
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		snapshotOptions.SetStart(*start)
+	}
+}
+
+// imageWaitFor lists the users of the images once for each region.  The users are stored on the image operations and
+// the images wait for the users being removed, see ResourceInstanceWrapper.waiting
+func imageWaitFor(ris []*ResourceInstanceWrapper) {
+	images := make(map[string]*ResourceInstanceWrapper)
+	regions := make(map[string]bool)
+	byCrn := make(map[string]*ResourceInstanceWrapper)
+	for _, ri := range ris {
+		byCrn[ri.crn.Crn] = ri
+		if ri.crn.resourceType == "is" && ri.crn.vpcType == "image" {
+			images[ri.crn.vpcId] = ri
+			regions[ri.crn.region] = true
+		}
+	}
+	for region := range regions {
+		client, err := MustGlobalContext().getVpcClientFromRegion(region)
+		if err != nil {
+			log.Print("imageWaitFor, getVpcClient err:", err)
+			continue
+		}
+		users, err := listImageUsers(client)
+		if err != nil {
+			log.Print("imageWaitFor, listImageUsers region:", region, " err:", err)
+			continue
+		}
+		for _, user := range users {
+			image, ok := images[user.imageID]
+			if !ok {
+				continue
+			}
+			if imageOperation, ok := image.operations.(*VpcGenericImageOperation); ok {
+				imageOperation.users = append(imageOperation.users, user.label)
+			}
+			if userRi, ok := byCrn[user.crn]; ok {
+				image.waitFor = append(image.waitFor, userRi)
+			}
+		}
+	}
+}
+
+// vpcImageUsers returns the resources that reference the image, nil if not an image
+func vpcImageUsers(ri *ResourceInstanceWrapper) []string {
+	if imageOperation, ok := ri.operations.(*VpcGenericImageOperation); ok {
+		return imageOperation.users
+	}
+	return nil
+}
+
+// Destroy deletes the image, the users being removed are deleted first, see imageWaitFor.  The delete fails while
+// other resources use the image
+func (vpc *VpcGenericImageOperation) Destroy(ri *ResourceInstanceWrapper) {
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericImageOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	if len(vpc.users) > 0 {
+		return FormatInstance(firstNonEmpty(vpc.operations.name, "--"), "vpc used-by:"+strings.Join(vpc.users, ","), *ri.crn)
+	}
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// imageRetireNeeded is true if an image in the status is moved along by the retirement, deprecated images can still
+// be obsoleted
+func imageRetireNeeded(status string, obsolete bool) bool {
+	switch status {
+	case vpcv1.ImageStatusAvailableConst:
+		return true
+	case vpcv1.ImageStatusDeprecatedConst:
+		return obsolete
+	}
+	return false
+}
+
+// ImageRetire deprecates, or obsoletes, the private images that ls would list instead of deleting them.  Deprecated
+// images can still be used to provision instances with a warning, obsolete images can not
+func ImageRetire(creds Credentials, accountID string, region string, resourceGroupName string, resourceGroupID string, obsolete bool, force bool, verbose bool) error {
+	if err := SetGlobalContextWithCredentials(creds, accountID, region, resourceGroupName, resourceGroupID, "", verbose); err != nil {
+		return err
+	}
+	wrappedResourceInstances, err := List(false)
+	if err != nil {
+		return err
+	}
+	images := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range wrappedResourceInstances {
+		if ri.crn.resourceType != "is" || ri.crn.vpcType != "image" || ri.state == SIStateDeleted {
+			continue
+		}
+		if metadata, ok := vpcMetadata(ri); ok && imageRetireNeeded(metadata.Status, obsolete) {
+			images = append(images, ri)
+		}
+	}
+	images = pruneProtected(images)
+	if len(images) == 0 {
+		fmt.Println("no images to retire")
+		return nil
+	}
+	lsOutput(images, os.Stdout, false)
+	action, retire := "Deprecate", func(client *vpcv1.VpcV1, id string) error {
+		_, err := client.DeprecateImage(client.NewDeprecateImageOptions(id))
+		return err
+	}
+	if obsolete {
+		action, retire = "Obsolete", func(client *vpcv1.VpcV1, id string) error {
+			_, err := client.ObsoleteImage(client.NewObsoleteImageOptions(id))
+			return err
+		}
+	}
+	if !force && !confirm(action+" these images?") {
+		return nil
+	}
+	failed := 0
+	for _, ri := range images {
+		client, err := MustGlobalContext().getVpcClient(ri.crn)
+		if err == nil {
+			err = retire(client, ri.crn.vpcId)
+		}
+		if err != nil {
+			failed++
+			log.Print("ImageRetire, ", strings.ToLower(action), " ", ri.crn.Crn, " err:", err)
+			continue
+		}
+		fmt.Println(strings.ToLower(action)+"d", ri.FormatInstance(false))
+	}
+	if failed > 0 {
+		return errors.New("some images not retired")
+	}
+	return nil
+}
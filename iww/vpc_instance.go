@@ -0,0 +1,183 @@
+package iww
+
+// instances are taken apart before they are deleted: floating ips are unbound and the data volumes that would
+// outlive the instance are detached.  Optionally the instance is stopped first, see Profile.StopInstances
+
+import (
+	"log"
+	"strings"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+type VpcGenericInstanceOperation struct {
+	operations  VpcGenericOperation
+	attachments []vpcv1.VolumeAttachment // from the last fetch
+}
+
+func (vpc *VpcGenericInstanceOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericInstanceOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+	if ri.state != SIStateExists {
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericInstanceOperation.Fetch, getVpcClient err:", err)
+		return
+	}
+	collection, _, err := client.ListInstanceVolumeAttachments(client.NewListInstanceVolumeAttachmentsOptions(ri.crn.vpcId))
+	if err != nil {
+		log.Print("VpcGenericInstanceOperation.Fetch, ListInstanceVolumeAttachments err:", err)
+		return
+	}
+	vpc.attachments = collection.VolumeAttachments
+}
+
+// instanceDestroyStep is the next step in the delete sequence of an instance
+const (
+	instanceDestroyWait   = "wait"   // instance is changing state, try again later
+	instanceDestroyStop   = "stop"   // stop the instance
+	instanceDestroyDetach = "detach" // detach the data volumes that are not deleted with the instance
+	instanceDestroyDelete = "delete" // unbind the floating ips and delete the instance
+)
+
+func instanceDestroyStep(instance *vpcv1.Instance, attachments []vpcv1.VolumeAttachment, stop bool) string {
+	switch vpcString(instance.Status) {
+	case vpcv1.InstanceStatusPendingConst, vpcv1.InstanceStatusStartingConst, vpcv1.InstanceStatusStoppingConst, vpcv1.InstanceStatusRestartingConst, vpcv1.InstanceStatusDeletingConst:
+		return instanceDestroyWait
+	case vpcv1.InstanceStatusRunningConst:
+		if stop {
+			return instanceDestroyStop
+		}
+	}
+	kept := instanceKeptVolumeAttachments(attachments)
+	for _, attachment := range kept {
+		switch vpcString(attachment.Status) {
+		case vpcv1.VolumeAttachmentStatusAttachingConst, vpcv1.VolumeAttachmentStatusDetachingConst, vpcv1.VolumeAttachmentStatusDeletingConst:
+			return instanceDestroyWait
+		}
+	}
+	if len(kept) > 0 {
+		return instanceDestroyDetach
+	}
+	return instanceDestroyDelete
+}
+
+// instanceKeptVolumeAttachments returns the data volume attachments of volumes that are not deleted with the instance
+func instanceKeptVolumeAttachments(attachments []vpcv1.VolumeAttachment) []vpcv1.VolumeAttachment {
+	ret := make([]vpcv1.VolumeAttachment, 0)
+	for _, attachment := range attachments {
+		if vpcString(attachment.Type) == vpcv1.VolumeAttachmentTypeBootConst {
+			continue
+		}
+		if attachment.DeleteVolumeOnInstanceDelete != nil && *attachment.DeleteVolumeOnInstanceDelete {
+			continue
+		}
+		ret = append(ret, attachment)
+	}
+	return ret
+}
+
+// instanceAutoDeleteVolumes returns the names of the volumes that are deleted with the instance, nil if not an instance
+func instanceAutoDeleteVolumes(ri *ResourceInstanceWrapper) []string {
+	instanceOperation, ok := ri.operations.(*VpcGenericInstanceOperation)
+	if !ok {
+		return nil
+	}
+	ret := make([]string, 0)
+	for _, attachment := range instanceOperation.attachments {
+		if attachment.Volume != nil && attachment.DeleteVolumeOnInstanceDelete != nil && *attachment.DeleteVolumeOnInstanceDelete {
+			ret = append(ret, firstNonEmpty(vpcString(attachment.Volume.Name), vpcString(attachment.Volume.ID)))
+		}
+	}
+	return ret
+}
+
+// unbindInstanceFloatingIps unbinds the floating ips of the network interfaces and the virtual network interfaces of
+// the network attachments
+func unbindInstanceFloatingIps(client *vpcv1.VpcV1, instance *vpcv1.Instance) {
+	for _, nic := range instance.NetworkInterfaces {
+		collection, _, err := client.ListInstanceNetworkInterfaceFloatingIps(client.NewListInstanceNetworkInterfaceFloatingIpsOptions(*instance.ID, *nic.ID))
+		if err != nil {
+			log.Print("unbindInstanceFloatingIps, ListInstanceNetworkInterfaceFloatingIps err:", err)
+			continue
+		}
+		for _, floatingIP := range collection.FloatingIps {
+			MustGlobalContext().verboseLogger.Print("unbind floating ip:", vpcString(floatingIP.Address), " instance:", *instance.ID)
+			if _, err := client.RemoveInstanceNetworkInterfaceFloatingIP(client.NewRemoveInstanceNetworkInterfaceFloatingIPOptions(*instance.ID, *nic.ID, *floatingIP.ID)); err != nil {
+				log.Print("unbindInstanceFloatingIps, RemoveInstanceNetworkInterfaceFloatingIP err:", err)
+			}
+		}
+	}
+	for _, attachment := range instance.NetworkAttachments {
+		if attachment.VirtualNetworkInterface == nil {
+			continue
+		}
+		vniID := *attachment.VirtualNetworkInterface.ID
+		options := client.NewListNetworkInterfaceFloatingIpsOptions(vniID)
+		for {
+			collection, _, err := client.ListNetworkInterfaceFloatingIps(options)
+			if err != nil {
+				log.Print("unbindInstanceFloatingIps, ListNetworkInterfaceFloatingIps err:", err)
+				break
+			}
+			for _, floatingIP := range collection.FloatingIps {
+				MustGlobalContext().verboseLogger.Print("unbind floating ip:", vpcString(floatingIP.Address), " instance:", *instance.ID)
+				if _, err := client.RemoveNetworkInterfaceFloatingIP(client.NewRemoveNetworkInterfaceFloatingIPOptions(vniID, *floatingIP.ID)); err != nil {
+					log.Print("unbindInstanceFloatingIps, RemoveNetworkInterfaceFloatingIP err:", err)
+				}
+			}
+			start, err := collection.GetNextStart()
+			if err != nil || start == nil {
+				break
+			}
+			options.SetStart(*start)
+		}
+	}
+}
+
+func (vpc *VpcGenericInstanceOperation) Destroy(ri *ResourceInstanceWrapper) {
+	instance, ok := ri.resource.(*vpcv1.Instance)
+	if !ok {
+		vpc.operations.Destroy(ri)
+		return
+	}
+	step := instanceDestroyStep(instance, vpc.attachments, MustGlobalContext().stopInstances)
+	MustGlobalContext().verboseLogger.Print("instance destroy step:", step, " ", ri.crn.Crn)
+	if step == instanceDestroyWait {
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericInstanceOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	switch step {
+	case instanceDestroyStop:
+		options := client.NewCreateInstanceActionOptions(ri.crn.vpcId, vpcv1.CreateInstanceActionOptionsTypeStopConst)
+		options.SetForce(true)
+		if _, _, err := client.CreateInstanceAction(options); err != nil {
+			log.Print("VpcGenericInstanceOperation.Destroy, CreateInstanceAction stop err:", err)
+		}
+	case instanceDestroyDetach:
+		for _, attachment := range instanceKeptVolumeAttachments(vpc.attachments) {
+			if _, err := client.DeleteInstanceVolumeAttachment(client.NewDeleteInstanceVolumeAttachmentOptions(ri.crn.vpcId, *attachment.ID)); err != nil {
+				log.Print("VpcGenericInstanceOperation.Destroy, DeleteInstanceVolumeAttachment err:", err)
+			}
+		}
+	case instanceDestroyDelete:
+		unbindInstanceFloatingIps(client, instance)
+		vpc.operations.Destroy(ri)
+	}
+}
+
+func (vpc *VpcGenericInstanceOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	if volumes := instanceAutoDeleteVolumes(ri); len(volumes) > 0 {
+		return FormatInstance(firstNonEmpty(vpc.operations.name, "--"), "vpc auto-delete:"+strings.Join(volumes, ","), *ri.crn)
+	}
+	return vpc.operations.FormatInstance(ri, fast)
+}
@@ -15,6 +15,34 @@ type VpcSubtypeOperations interface {
 	Destroy(service *vpcv1.VpcV1, id string) ( /*response*/ interface{}, error)
 }
 
+// VpcResourceMetadata is extracted from a vpc resource returned by Get or List, strings are "" if not available
+type VpcResourceMetadata struct {
+	ID              string
+	Name            string
+	Vpcid           string
+	Status          string // status or lifecycle_state
+	Zone            string
+	CreatedAt       string
+	CRN             string
+	ResourceGroupID string
+	Resource        interface{} // *vpcv1.VPC, *vpcv1.Subnet, ...
+}
+
+// VpcSubtypeListOperations are implemented by the regular vpc resource types
+type VpcSubtypeListOperations interface {
+	// List all of the resources in the region, only the resource group if resourceGroupID is not ""
+	List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error)
+	// Metadata of the resource, false if the resource is not the expected type
+	Metadata(resource interface{}) (VpcResourceMetadata, bool)
+}
+
+func vpcString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 type VpcSpecificVPCInstance struct{}
 
 func (vpc *VpcSpecificVPCInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -34,6 +62,55 @@ func (spec *VpcSpecificVPCInstance) Get(service *vpcv1.VpcV1, id string) (string
 	}
 }
 
+func (spec *VpcSpecificVPCInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.VPC)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	return ret, true
+}
+
+func (spec *VpcSpecificVPCInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListVpcsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListVpcs(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Vpcs {
+			metadata, _ := spec.Metadata(&collection.Vpcs[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificSubnetInstance struct{}
 
 func (vpc *VpcSpecificSubnetInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -53,6 +130,61 @@ func (spec *VpcSpecificSubnetInstance) Get(service *vpcv1.VpcV1, id string) (str
 	}
 }
 
+func (spec *VpcSpecificSubnetInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Subnet)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificSubnetInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListSubnetsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListSubnets(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Subnets {
+			metadata, _ := spec.Metadata(&collection.Subnets[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificInstanceInstance struct{}
 
 func (vpc *VpcSpecificInstanceInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -72,6 +204,61 @@ func (spec *VpcSpecificInstanceInstance) Get(service *vpcv1.VpcV1, id string) (s
 	}
 }
 
+func (spec *VpcSpecificInstanceInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Instance)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificInstanceInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListInstancesOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListInstances(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Instances {
+			metadata, _ := spec.Metadata(&collection.Instances[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificVolumeInstance struct{}
 
 func (vpc *VpcSpecificVolumeInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -91,6 +278,55 @@ func (spec *VpcSpecificVolumeInstance) Get(service *vpcv1.VpcV1, id string) (str
 	}
 }
 
+func (spec *VpcSpecificVolumeInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Volume)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificVolumeInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListVolumesOptions()
+	for {
+		collection, _, err := service.ListVolumes(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Volumes {
+			metadata, _ := spec.Metadata(&collection.Volumes[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificKeyInstance struct{}
 
 func (vpc *VpcSpecificKeyInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -110,6 +346,51 @@ func (spec *VpcSpecificKeyInstance) Get(service *vpcv1.VpcV1, id string) (string
 	}
 }
 
+func (spec *VpcSpecificKeyInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Key)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificKeyInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListKeysOptions()
+	for {
+		collection, _, err := service.ListKeys(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Keys {
+			metadata, _ := spec.Metadata(&collection.Keys[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificLoadBalancerInstance struct{}
 
 func (vpc *VpcSpecificLoadBalancerInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -129,6 +410,52 @@ func (spec *VpcSpecificLoadBalancerInstance) Get(service *vpcv1.VpcV1, id string
 	}
 }
 
+func (spec *VpcSpecificLoadBalancerInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.LoadBalancer)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.ProvisioningStatus)
+	return ret, true
+}
+
+func (spec *VpcSpecificLoadBalancerInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListLoadBalancersOptions()
+	for {
+		collection, _, err := service.ListLoadBalancers(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.LoadBalancers {
+			metadata, _ := spec.Metadata(&collection.LoadBalancers[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificFloatingIPInstance struct{}
 
 func (vpc *VpcSpecificFloatingIPInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -148,6 +475,58 @@ func (spec *VpcSpecificFloatingIPInstance) Get(service *vpcv1.VpcV1, id string)
 	}
 }
 
+func (spec *VpcSpecificFloatingIPInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.FloatingIP)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificFloatingIPInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListFloatingIpsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListFloatingIps(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.FloatingIps {
+			metadata, _ := spec.Metadata(&collection.FloatingIps[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificImageInstance struct{}
 
 func (vpc *VpcSpecificImageInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -167,6 +546,56 @@ func (spec *VpcSpecificImageInstance) Get(service *vpcv1.VpcV1, id string) (stri
 	}
 }
 
+func (spec *VpcSpecificImageInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Image)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	return ret, true
+}
+
+func (spec *VpcSpecificImageInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListImagesOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	options.SetVisibility("private")
+	for {
+		collection, _, err := service.ListImages(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Images {
+			metadata, _ := spec.Metadata(&collection.Images[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificPublicGatewayInstance struct{}
 
 func (vpc *VpcSpecificPublicGatewayInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -186,6 +615,61 @@ func (spec *VpcSpecificPublicGatewayInstance) Get(service *vpcv1.VpcV1, id strin
 	}
 }
 
+func (spec *VpcSpecificPublicGatewayInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.PublicGateway)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificPublicGatewayInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListPublicGatewaysOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListPublicGateways(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.PublicGateways {
+			metadata, _ := spec.Metadata(&collection.PublicGateways[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificNetworkACLInstance struct{}
 
 func (vpc *VpcSpecificNetworkACLInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -205,6 +689,57 @@ func (spec *VpcSpecificNetworkACLInstance) Get(service *vpcv1.VpcV1, id string)
 	}
 }
 
+func (spec *VpcSpecificNetworkACLInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.NetworkACL)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificNetworkACLInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListNetworkAclsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListNetworkAcls(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.NetworkAcls {
+			metadata, _ := spec.Metadata(&collection.NetworkAcls[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificSecurityGroupInstance struct{}
 
 func (vpc *VpcSpecificSecurityGroupInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -224,6 +759,57 @@ func (spec *VpcSpecificSecurityGroupInstance) Get(service *vpcv1.VpcV1, id strin
 	}
 }
 
+func (spec *VpcSpecificSecurityGroupInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.SecurityGroup)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificSecurityGroupInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListSecurityGroupsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListSecurityGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.SecurityGroups {
+			metadata, _ := spec.Metadata(&collection.SecurityGroups[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificFlowLogCollectorInstance struct{}
 
 func (vpc *VpcSpecificFlowLogCollectorInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -243,6 +829,58 @@ func (spec *VpcSpecificFlowLogCollectorInstance) Get(service *vpcv1.VpcV1, id st
 	}
 }
 
+func (spec *VpcSpecificFlowLogCollectorInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.FlowLogCollector)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificFlowLogCollectorInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListFlowLogCollectorsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListFlowLogCollectors(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.FlowLogCollectors {
+			metadata, _ := spec.Metadata(&collection.FlowLogCollectors[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificInstanceGroupInstance struct{}
 
 func (vpc *VpcSpecificInstanceGroupInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -262,6 +900,55 @@ func (spec *VpcSpecificInstanceGroupInstance) Get(service *vpcv1.VpcV1, id strin
 	}
 }
 
+func (spec *VpcSpecificInstanceGroupInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.InstanceGroup)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	return ret, true
+}
+
+func (spec *VpcSpecificInstanceGroupInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListInstanceGroupsOptions()
+	for {
+		collection, _, err := service.ListInstanceGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.InstanceGroups {
+			metadata, _ := spec.Metadata(&collection.InstanceGroups[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 type VpcSpecificSnapshotInstance struct{}
 
 func (vpc *VpcSpecificSnapshotInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
@@ -281,19 +968,858 @@ func (spec *VpcSpecificSnapshotInstance) Get(service *vpcv1.VpcV1, id string) (s
 	}
 }
 
+func (spec *VpcSpecificSnapshotInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Snapshot)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificSnapshotInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListSnapshotsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListSnapshots(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Snapshots {
+			metadata, _ := spec.Metadata(&collection.Snapshots[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificSnapshotConsistencyGroupInstance struct{}
+
+func (vpc *VpcSpecificSnapshotConsistencyGroupInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	_, response, err := service.DeleteSnapshotConsistencyGroup(service.NewDeleteSnapshotConsistencyGroupOptions(id))
+	return response, err
+}
+
+func (spec *VpcSpecificSnapshotConsistencyGroupInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetSnapshotConsistencyGroup(service.NewGetSnapshotConsistencyGroupOptions(id))
+	if err == nil {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificSnapshotConsistencyGroupInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.SnapshotConsistencyGroup)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificSnapshotConsistencyGroupInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListSnapshotConsistencyGroupsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListSnapshotConsistencyGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.SnapshotConsistencyGroups {
+			metadata, _ := spec.Metadata(&collection.SnapshotConsistencyGroups[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificBareMetalServerInstance struct{}
+
+func (vpc *VpcSpecificBareMetalServerInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteBareMetalServer(service.NewDeleteBareMetalServerOptions(id))
+}
+
+func (spec *VpcSpecificBareMetalServerInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetBareMetalServer(service.NewGetBareMetalServerOptions(id))
+	if err == nil {
+		return *instance.Name, *instance.VPC.ID, true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificBareMetalServerInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.BareMetalServer)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.Status)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificBareMetalServerInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListBareMetalServersOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListBareMetalServers(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.BareMetalServers {
+			metadata, _ := spec.Metadata(&collection.BareMetalServers[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificDedicatedHostInstance struct{}
+
+func (vpc *VpcSpecificDedicatedHostInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteDedicatedHost(service.NewDeleteDedicatedHostOptions(id))
+}
+
+func (spec *VpcSpecificDedicatedHostInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetDedicatedHost(service.NewGetDedicatedHostOptions(id))
+	if err == nil {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificDedicatedHostInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.DedicatedHost)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificDedicatedHostInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListDedicatedHostsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListDedicatedHosts(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.DedicatedHosts {
+			metadata, _ := spec.Metadata(&collection.DedicatedHosts[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificDedicatedHostGroupInstance struct{}
+
+func (vpc *VpcSpecificDedicatedHostGroupInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteDedicatedHostGroup(service.NewDeleteDedicatedHostGroupOptions(id))
+}
+
+func (spec *VpcSpecificDedicatedHostGroupInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetDedicatedHostGroup(service.NewGetDedicatedHostGroupOptions(id))
+	if err == nil {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificDedicatedHostGroupInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.DedicatedHostGroup)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificDedicatedHostGroupInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListDedicatedHostGroupsOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListDedicatedHostGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Groups {
+			metadata, _ := spec.Metadata(&collection.Groups[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificPlacementGroupInstance struct{}
+
+func (vpc *VpcSpecificPlacementGroupInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeletePlacementGroup(service.NewDeletePlacementGroupOptions(id))
+}
+
+func (spec *VpcSpecificPlacementGroupInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetPlacementGroup(service.NewGetPlacementGroupOptions(id))
+	if err == nil {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificPlacementGroupInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.PlacementGroup)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificPlacementGroupInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListPlacementGroupsOptions()
+	for {
+		collection, _, err := service.ListPlacementGroups(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.PlacementGroups {
+			metadata, _ := spec.Metadata(&collection.PlacementGroups[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificEndpointGatewayInstance struct{}
+
+func (vpc *VpcSpecificEndpointGatewayInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteEndpointGateway(service.NewDeleteEndpointGatewayOptions(id))
+}
+
+func (spec *VpcSpecificEndpointGatewayInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetEndpointGateway(service.NewGetEndpointGatewayOptions(id))
+	if err == nil {
+		return *instance.Name, *instance.VPC.ID, true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificEndpointGatewayInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.EndpointGateway)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificEndpointGatewayInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListEndpointGatewaysOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListEndpointGateways(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.EndpointGateways {
+			metadata, _ := spec.Metadata(&collection.EndpointGateways[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificShareInstance struct{}
+
+func (vpc *VpcSpecificShareInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	_, response, err := service.DeleteShare(service.NewDeleteShareOptions(id))
+	return response, err
+}
+
+func (spec *VpcSpecificShareInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetShare(service.NewGetShareOptions(id))
+	if err == nil {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificShareInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.Share)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificShareInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListSharesOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListShares(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Shares {
+			metadata, _ := spec.Metadata(&collection.Shares[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificBackupPolicyInstance struct{}
+
+func (vpc *VpcSpecificBackupPolicyInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	_, response, err := service.DeleteBackupPolicy(service.NewDeleteBackupPolicyOptions(id))
+	return response, err
+}
+
+func (spec *VpcSpecificBackupPolicyInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	resource, response, err := service.GetBackupPolicy(service.NewGetBackupPolicyOptions(id))
+	instance, ok := resource.(*vpcv1.BackupPolicy)
+	if err == nil && ok {
+		return *instance.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificBackupPolicyInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.BackupPolicy)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificBackupPolicyInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListBackupPoliciesOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListBackupPolicies(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.BackupPolicies {
+			metadata, _ := spec.Metadata(collection.BackupPolicies[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificVPNServerInstance struct{}
+
+func (vpc *VpcSpecificVPNServerInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteVPNServer(service.NewDeleteVPNServerOptions(id))
+}
+
+func (spec *VpcSpecificVPNServerInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetVPNServer(service.NewGetVPNServerOptions(id))
+	if err == nil {
+		return *instance.Name, *instance.VPC.ID, true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificVPNServerInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.VPNServer)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificVPNServerInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListVPNServersOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListVPNServers(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.VPNServers {
+			metadata, _ := spec.Metadata(&collection.VPNServers[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificVirtualNetworkInterfaceInstance struct{}
+
+func (vpc *VpcSpecificVirtualNetworkInterfaceInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	_, response, err := service.DeleteVirtualNetworkInterfaces(service.NewDeleteVirtualNetworkInterfacesOptions(id))
+	return response, err
+}
+
+func (spec *VpcSpecificVirtualNetworkInterfaceInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetVirtualNetworkInterface(service.NewGetVirtualNetworkInterfaceOptions(id))
+	if err == nil {
+		return *instance.Name, *instance.VPC.ID, true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificVirtualNetworkInterfaceInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.VirtualNetworkInterface)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	if instance.Zone != nil {
+		ret.Zone = vpcString(instance.Zone.Name)
+	}
+	return ret, true
+}
+
+func (spec *VpcSpecificVirtualNetworkInterfaceInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListVirtualNetworkInterfacesOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListVirtualNetworkInterfaces(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.VirtualNetworkInterfaces {
+			metadata, _ := spec.Metadata(&collection.VirtualNetworkInterfaces[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+type VpcSpecificPrivatePathServiceGatewayInstance struct{}
+
+func (vpc *VpcSpecificPrivatePathServiceGatewayInstance) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeletePrivatePathServiceGateway(service.NewDeletePrivatePathServiceGatewayOptions(id))
+}
+
+func (spec *VpcSpecificPrivatePathServiceGatewayInstance) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	instance, response, err := service.GetPrivatePathServiceGateway(service.NewGetPrivatePathServiceGatewayOptions(id))
+	if err == nil {
+		return *instance.Name, *instance.VPC.ID, true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+func (spec *VpcSpecificPrivatePathServiceGatewayInstance) Metadata(resource interface{}) (VpcResourceMetadata, bool) {
+	instance, ok := resource.(*vpcv1.PrivatePathServiceGateway)
+	if !ok || instance == nil {
+		return VpcResourceMetadata{}, false
+	}
+	ret := VpcResourceMetadata{
+		ID:       vpcString(instance.ID),
+		Name:     vpcString(instance.Name),
+		CRN:      vpcString(instance.CRN),
+		Resource: instance,
+	}
+	if instance.CreatedAt != nil {
+		ret.CreatedAt = instance.CreatedAt.String()
+	}
+	if instance.ResourceGroup != nil {
+		ret.ResourceGroupID = vpcString(instance.ResourceGroup.ID)
+	}
+	if instance.VPC != nil {
+		ret.Vpcid = vpcString(instance.VPC.ID)
+	}
+	ret.Status = vpcString(instance.LifecycleState)
+	return ret, true
+}
+
+func (spec *VpcSpecificPrivatePathServiceGatewayInstance) List(service *vpcv1.VpcV1, resourceGroupID string) ([]VpcResourceMetadata, error) {
+	ret := make([]VpcResourceMetadata, 0)
+	options := service.NewListPrivatePathServiceGatewaysOptions()
+	if resourceGroupID != "" {
+		options.SetResourceGroupID(resourceGroupID)
+	}
+	for {
+		collection, _, err := service.ListPrivatePathServiceGateways(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.PrivatePathServiceGateways {
+			metadata, _ := spec.Metadata(&collection.PrivatePathServiceGateways[i])
+			if resourceGroupID == "" || metadata.ResourceGroupID == resourceGroupID {
+				ret = append(ret, metadata)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
 var VpcSubtypeOperationsMap = map[string]VpcSubtypeOperations{
-	"vpc":                &VpcSpecificVPCInstance{},
-	"subnet":             &VpcSpecificSubnetInstance{},
-	"instance":           &VpcSpecificInstanceInstance{},
-	"volume":             &VpcSpecificVolumeInstance{},
-	"key":                &VpcSpecificKeyInstance{},
-	"load-balancer":      &VpcSpecificLoadBalancerInstance{},
-	"floating-ip":        &VpcSpecificFloatingIPInstance{},
-	"image":              &VpcSpecificImageInstance{},
-	"public-gateway":     &VpcSpecificPublicGatewayInstance{},
-	"network-acl":        &VpcSpecificNetworkACLInstance{},
-	"security-group":     &VpcSpecificSecurityGroupInstance{},
-	"flow-log-collector": &VpcSpecificFlowLogCollectorInstance{},
-	"instance-group":     &VpcSpecificInstanceGroupInstance{},
-	"snapshot":           &VpcSpecificSnapshotInstance{},
+	"vpc":                          &VpcSpecificVPCInstance{},
+	"subnet":                       &VpcSpecificSubnetInstance{},
+	"instance":                     &VpcSpecificInstanceInstance{},
+	"volume":                       &VpcSpecificVolumeInstance{},
+	"key":                          &VpcSpecificKeyInstance{},
+	"load-balancer":                &VpcSpecificLoadBalancerInstance{},
+	"floating-ip":                  &VpcSpecificFloatingIPInstance{},
+	"image":                        &VpcSpecificImageInstance{},
+	"public-gateway":               &VpcSpecificPublicGatewayInstance{},
+	"network-acl":                  &VpcSpecificNetworkACLInstance{},
+	"security-group":               &VpcSpecificSecurityGroupInstance{},
+	"flow-log-collector":           &VpcSpecificFlowLogCollectorInstance{},
+	"instance-group":               &VpcSpecificInstanceGroupInstance{},
+	"snapshot":                     &VpcSpecificSnapshotInstance{},
+	"snapshot-consistency-group":   &VpcSpecificSnapshotConsistencyGroupInstance{},
+	"bare-metal-server":            &VpcSpecificBareMetalServerInstance{},
+	"dedicated-host":               &VpcSpecificDedicatedHostInstance{},
+	"dedicated-host-group":         &VpcSpecificDedicatedHostGroupInstance{},
+	"placement-group":              &VpcSpecificPlacementGroupInstance{},
+	"endpoint-gateway":             &VpcSpecificEndpointGatewayInstance{},
+	"share":                        &VpcSpecificShareInstance{},
+	"backup-policy":                &VpcSpecificBackupPolicyInstance{},
+	"vpn-server":                   &VpcSpecificVPNServerInstance{},
+	"virtual-network-interface":    &VpcSpecificVirtualNetworkInterfaceInstance{},
+	"private-path-service-gateway": &VpcSpecificPrivatePathServiceGatewayInstance{},
 }
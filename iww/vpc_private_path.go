@@ -0,0 +1,205 @@
+package iww
+
+// standalone virtual network interfaces, private path service gateways and the endpoint gateway bindings of the
+// private path service gateways.  Bindings do not have a crn.  The fake crn has the private path service gateway id
+// in the id and the binding id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:GATEWAYID:private-path-service-gateway-binding:BINDINGID
+
+import (
+	"log"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const vpcTypePrivatePathBinding = "private-path-service-gateway-binding"
+
+// vpcDeletedWithTarget is true for a resource that is deleted with the resource it is attached to, like the virtual
+// network interface of an instance
+func vpcDeletedWithTarget(resource interface{}) bool {
+	if vni, ok := resource.(*vpcv1.VirtualNetworkInterface); ok {
+		return vni.Target != nil && vni.AutoDelete != nil && *vni.AutoDelete
+	}
+	return false
+}
+
+// --------------------------------------
+// virtual network interfaces are deleted after they are detached from their target, like a bare metal server
+type VpcGenericVirtualNetworkInterfaceOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericVirtualNetworkInterfaceOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericVirtualNetworkInterfaceOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericVirtualNetworkInterfaceOperation) Destroy(ri *ResourceInstanceWrapper) {
+	if vni, ok := ri.resource.(*vpcv1.VirtualNetworkInterface); ok && vni.Target != nil {
+		MustGlobalContext().verboseLogger.Print("virtual network interface waiting for target delete:", ri.crn.Crn)
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericVirtualNetworkInterfaceOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// private path service gateways are unpublished and their endpoint gateway bindings denied before they are
+// deleted.  The load balancer of the gateway can not be deleted until the gateway is gone
+type VpcGenericPrivatePathServiceGatewayOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericPrivatePathServiceGatewayOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericPrivatePathServiceGatewayOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+// privatePathBindingActive is true if the endpoint gateway binding must be denied before the gateway is deleted
+func privatePathBindingActive(binding *vpcv1.PrivatePathServiceGatewayEndpointGatewayBinding) bool {
+	switch vpcString(binding.Status) {
+	case vpcv1.PrivatePathServiceGatewayEndpointGatewayBindingStatusPendingConst, vpcv1.PrivatePathServiceGatewayEndpointGatewayBindingStatusPermittedConst:
+		return true
+	}
+	return false
+}
+
+// privatePathBindings returns the active endpoint gateway bindings of the private path service gateway
+func privatePathBindings(client *vpcv1.VpcV1, gatewayID string) ([]*vpcv1.PrivatePathServiceGatewayEndpointGatewayBinding, error) {
+	ret := make([]*vpcv1.PrivatePathServiceGatewayEndpointGatewayBinding, 0)
+	options := client.NewListPrivatePathServiceGatewayEndpointGatewayBindingsOptions(gatewayID)
+	for {
+		collection, _, err := client.ListPrivatePathServiceGatewayEndpointGatewayBindings(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.EndpointGatewayBindings {
+			binding := &collection.EndpointGatewayBindings[i]
+			if privatePathBindingActive(binding) {
+				ret = append(ret, binding)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+func (vpc *VpcGenericPrivatePathServiceGatewayOperation) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericPrivatePathServiceGatewayOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	gatewayID := ri.crn.vpcId
+	if gateway, ok := ri.resource.(*vpcv1.PrivatePathServiceGateway); ok && gateway.Published != nil && *gateway.Published {
+		MustGlobalContext().verboseLogger.Print("unpublish private path service gateway:", ri.crn.Crn)
+		if _, err := client.UnpublishPrivatePathServiceGateway(client.NewUnpublishPrivatePathServiceGatewayOptions(gatewayID)); err != nil {
+			log.Print("VpcGenericPrivatePathServiceGatewayOperation.Destroy, UnpublishPrivatePathServiceGateway err:", err)
+		}
+		return
+	}
+	bindings, err := privatePathBindings(client, gatewayID)
+	if err != nil {
+		log.Print("VpcGenericPrivatePathServiceGatewayOperation.Destroy, ListPrivatePathServiceGatewayEndpointGatewayBindings err:", err)
+		return
+	}
+	if len(bindings) > 0 {
+		for _, binding := range bindings {
+			if _, err := client.DenyPrivatePathServiceGatewayEndpointGatewayBinding(client.NewDenyPrivatePathServiceGatewayEndpointGatewayBindingOptions(gatewayID, *binding.ID)); err != nil {
+				log.Print("VpcGenericPrivatePathServiceGatewayOperation.Destroy, DenyPrivatePathServiceGatewayEndpointGatewayBinding err:", err)
+			}
+		}
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericPrivatePathServiceGatewayOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// endpoint gateway bindings are not deleted, they are denied and the endpoint gateway in the other account stops working
+type VpcPrivatePathBindingOperations struct {
+	name  string
+	vpcid string // of the private path service gateway
+}
+
+func (binding *VpcPrivatePathBindingOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcPrivatePathBindingOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	endpointGatewayBinding, response, err := client.GetPrivatePathServiceGatewayEndpointGatewayBinding(client.NewGetPrivatePathServiceGatewayEndpointGatewayBindingOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcPrivatePathBindingOperations.Fetch, GetPrivatePathServiceGatewayEndpointGatewayBinding err:", err)
+		}
+		return
+	}
+	ri.resource = endpointGatewayBinding
+	if privatePathBindingActive(endpointGatewayBinding) {
+		ri.state = SIStateExists
+	} else {
+		ri.state = SIStateDeleted
+	}
+}
+
+func (binding *VpcPrivatePathBindingOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcPrivatePathBindingOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	if _, err = client.DenyPrivatePathServiceGatewayEndpointGatewayBinding(client.NewDenyPrivatePathServiceGatewayEndpointGatewayBindingOptions(ri.crn.id, ri.crn.vpcId)); err != nil {
+		log.Print("VpcPrivatePathBindingOperations.Destroy, DenyPrivatePathServiceGatewayEndpointGatewayBinding err:", err)
+	}
+}
+
+func (binding *VpcPrivatePathBindingOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(binding.name, "--"), "vpc", *ri.crn)
+}
+
+func (binding *VpcPrivatePathBindingOperations) Vpcid() string {
+	return binding.vpcid
+}
+
+// listPrivatePathBindings returns the active endpoint gateway bindings of the private path service gateway, the
+// name is the account of the endpoint gateway
+func listPrivatePathBindings(client *vpcv1.VpcV1, gateway *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	bindings, err := privatePathBindings(client, gateway.crn.vpcId)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ResourceInstanceWrapper, 0, len(bindings))
+	for _, binding := range bindings {
+		name := ""
+		if binding.Account != nil {
+			name = vpcString(binding.Account.ID)
+		}
+		crn := NewFakeCrn("is", gateway.crn.vpcId, vpcTypePrivatePathBinding, *binding.ID, gateway.crn.region)
+		ri := NewResourceInstanceWrapper(crn, gateway.ResourceGroupID, &name)
+		ri.operations = &VpcPrivatePathBindingOperations{name: name, vpcid: vpcidOf(gateway)}
+		ri.resource = binding
+		ri.parent = gateway
+		ret = append(ret, ri)
+	}
+	return ret, nil
+}
@@ -0,0 +1,161 @@
+package iww
+
+// subnet reserved ips, they do not have a crn.  The fake crn has the subnet id in the id and the reserved ip id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:SUBNETID:reserved-ip:RESERVEDIPID
+
+import (
+	"log"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const vpcTypeReservedIP = "reserved-ip"
+
+type VpcReservedIPOperations struct {
+	subnet *ResourceInstanceWrapper
+	name   string
+}
+
+func (reservedIP *VpcReservedIPOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcReservedIPOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	ip, response, err := client.GetSubnetReservedIP(client.NewGetSubnetReservedIPOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcReservedIPOperations.Fetch, GetSubnetReservedIP err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = ip
+	reservedIP.name = vpcString(ip.Name)
+}
+
+// Destroy releases the reserved ip if it is not bound, a bound reserved ip is released or deleted with its target
+func (reservedIP *VpcReservedIPOperations) Destroy(ri *ResourceInstanceWrapper) {
+	if ip, ok := ri.resource.(*vpcv1.ReservedIP); ok && ip.Target != nil {
+		MustGlobalContext().verboseLogger.Print("reserved ip bound to a target:", ri.crn.Crn)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcReservedIPOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	_, err = client.DeleteSubnetReservedIP(client.NewDeleteSubnetReservedIPOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		log.Print("VpcReservedIPOperations.Destroy, DeleteSubnetReservedIP err:", err)
+	}
+}
+
+func (reservedIP *VpcReservedIPOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	name := "--"
+	if reservedIP.name != "" {
+		name = reservedIP.name
+	}
+	return FormatInstance(name, "vpc", *ri.crn)
+}
+
+// Vpcid is the vpc of the subnet
+func (reservedIP *VpcReservedIPOperations) Vpcid() string {
+	return vpcidOf(reservedIP.subnet)
+}
+
+// subnetReservedIPs returns the user owned reserved ips in the subnet
+func subnetReservedIPs(client *vpcv1.VpcV1, subnetID string) ([]*vpcv1.ReservedIP, error) {
+	ret := make([]*vpcv1.ReservedIP, 0)
+	options := client.NewListSubnetReservedIpsOptions(subnetID)
+	for {
+		collection, _, err := client.ListSubnetReservedIps(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.ReservedIps {
+			ip := &collection.ReservedIps[i]
+			if vpcString(ip.Owner) == vpcv1.ReservedIPOwnerUserConst {
+				ret = append(ret, ip)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+// listReservedIPs returns the reserved ips of the subnet
+func listReservedIPs(client *vpcv1.VpcV1, subnet *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	ips, err := subnetReservedIPs(client, subnet.crn.vpcId)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ResourceInstanceWrapper, 0, len(ips))
+	for _, ip := range ips {
+		ret = append(ret, newReservedIP(subnet, ip))
+	}
+	return ret, nil
+}
+
+func newReservedIP(subnet *ResourceInstanceWrapper, ip *vpcv1.ReservedIP) *ResourceInstanceWrapper {
+	crn := NewFakeCrn("is", subnet.crn.vpcId, vpcTypeReservedIP, *ip.ID, subnet.crn.region)
+	ri := NewResourceInstanceWrapper(crn, subnet.ResourceGroupID, ip.Name)
+	ri.operations = &VpcReservedIPOperations{subnet: subnet, name: vpcString(ip.Name)}
+	ri.resource = ip
+	ri.parent = subnet
+	return ri
+}
+
+// releaseOrphanedReservedIPs deletes the unbound reserved ips in the subnet, they would block the subnet delete
+func releaseOrphanedReservedIPs(client *vpcv1.VpcV1, subnetID string) {
+	ips, err := subnetReservedIPs(client, subnetID)
+	if err != nil {
+		log.Print("releaseOrphanedReservedIPs, ListSubnetReservedIps err:", err)
+		return
+	}
+	for _, ip := range ips {
+		if ip.Target != nil {
+			continue
+		}
+		MustGlobalContext().verboseLogger.Print("release reserved ip:", vpcString(ip.Address), " subnet:", subnetID)
+		if _, err := client.DeleteSubnetReservedIP(client.NewDeleteSubnetReservedIPOptions(subnetID, *ip.ID)); err != nil {
+			log.Print("releaseOrphanedReservedIPs, DeleteSubnetReservedIP err:", err)
+		}
+	}
+}
+
+// --------------------------------------
+// subnets release the orphaned reserved ips before they are deleted
+type VpcGenericSubnetOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericSubnetOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericSubnetOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericSubnetOperation) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericSubnetOperation.Destroy, getVpcClient err:", err)
+	} else {
+		releaseOrphanedReservedIPs(client, ri.crn.vpcId)
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericSubnetOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
@@ -0,0 +1,309 @@
+package iww
+
+// custom routing tables and the user routes in all routing tables of a vpc.  Neither have a crn.  The fake crn has
+// the vpc id in the id and the routing table or route id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:VPCID:routing-table:ROUTINGTABLEID
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:VPCID:routing-table-route:ROUTEID
+
+import (
+	"log"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const (
+	vpcTypeRoutingTable      = "routing-table"
+	vpcTypeRoutingTableRoute = "routing-table-route"
+)
+
+// --------------------------------------
+type VpcRoutingTableOperations struct {
+	name  string
+	vpcid string
+}
+
+func (table *VpcRoutingTableOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcRoutingTableOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	routingTable, response, err := client.GetVPCRoutingTable(client.NewGetVPCRoutingTableOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcRoutingTableOperations.Fetch, GetVPCRoutingTable err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = routingTable
+	table.name = vpcString(routingTable.Name)
+}
+
+// routingTableIngress is true if the routing table is used for ingress routing, like from a transit gateway
+func routingTableIngress(routingTable *vpcv1.RoutingTable) bool {
+	for _, ingress := range []*bool{routingTable.RouteDirectLinkIngress, routingTable.RouteInternetIngress, routingTable.RouteTransitGatewayIngress, routingTable.RouteVPCZoneIngress} {
+		if ingress != nil && *ingress {
+			return true
+		}
+	}
+	return false
+}
+
+// Destroy the routing table after the subnets are attached to the default routing table and ingress routing is off
+func (table *VpcRoutingTableOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcRoutingTableOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	vpcid := ri.crn.id
+	if routingTable, ok := ri.resource.(*vpcv1.RoutingTable); ok {
+		if len(routingTable.Subnets) > 0 {
+			defaultTable, _, err := client.GetVPCDefaultRoutingTable(client.NewGetVPCDefaultRoutingTableOptions(vpcid))
+			if err != nil {
+				log.Print("VpcRoutingTableOperations.Destroy, GetVPCDefaultRoutingTable err:", err)
+				return
+			}
+			for _, subnet := range routingTable.Subnets {
+				MustGlobalContext().verboseLogger.Print("detach routing table from subnet:", vpcString(subnet.Name), " ", ri.crn.Crn)
+				_, _, err := client.ReplaceSubnetRoutingTable(client.NewReplaceSubnetRoutingTableOptions(*subnet.ID, &vpcv1.RoutingTableIdentityByID{ID: defaultTable.ID}))
+				if err != nil {
+					log.Print("VpcRoutingTableOperations.Destroy, ReplaceSubnetRoutingTable err:", err)
+				}
+			}
+			return
+		}
+		if routingTableIngress(routingTable) {
+			off := false
+			routingTablePatch, err := (&vpcv1.RoutingTablePatch{
+				RouteDirectLinkIngress:     &off,
+				RouteInternetIngress:       &off,
+				RouteTransitGatewayIngress: &off,
+				RouteVPCZoneIngress:        &off,
+			}).AsPatch()
+			if err != nil {
+				log.Print("VpcRoutingTableOperations.Destroy, AsPatch err:", err)
+				return
+			}
+			if _, _, err = client.UpdateVPCRoutingTable(client.NewUpdateVPCRoutingTableOptions(vpcid, ri.crn.vpcId, routingTablePatch)); err != nil {
+				log.Print("VpcRoutingTableOperations.Destroy, UpdateVPCRoutingTable err:", err)
+				return
+			}
+		}
+	}
+	if _, err = client.DeleteVPCRoutingTable(client.NewDeleteVPCRoutingTableOptions(vpcid, ri.crn.vpcId)); err != nil {
+		log.Print("VpcRoutingTableOperations.Destroy, DeleteVPCRoutingTable err:", err)
+	}
+}
+
+func (table *VpcRoutingTableOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(table.name, "--"), "vpc", *ri.crn)
+}
+
+func (table *VpcRoutingTableOperations) Vpcid() string {
+	return table.vpcid
+}
+
+// --------------------------------------
+type VpcRouteOperations struct {
+	name           string
+	vpcid          string
+	routingTableID string
+}
+
+func (route *VpcRouteOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcRouteOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	vpcRoute, response, err := client.GetVPCRoutingTableRoute(client.NewGetVPCRoutingTableRouteOptions(ri.crn.id, route.routingTableID, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcRouteOperations.Fetch, GetVPCRoutingTableRoute err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = vpcRoute
+	route.name = vpcString(vpcRoute.Name)
+}
+
+func (route *VpcRouteOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcRouteOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	if _, err = client.DeleteVPCRoutingTableRoute(client.NewDeleteVPCRoutingTableRouteOptions(ri.crn.id, route.routingTableID, ri.crn.vpcId)); err != nil {
+		log.Print("VpcRouteOperations.Destroy, DeleteVPCRoutingTableRoute err:", err)
+	}
+}
+
+func (route *VpcRouteOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(route.name, "--"), "vpc", *ri.crn)
+}
+
+func (route *VpcRouteOperations) Vpcid() string {
+	return route.vpcid
+}
+
+// listRoutingTables returns the custom routing tables of the vpc and the user routes of all the routing tables.  The
+// parent of a route in the default routing table is the vpc.  The resources that must be removed after a routing table
+// or route wait for it: the attached subnets wait for the routing table and the instance or endpoint gateway (VPE)
+// that is the next hop waits for the route.  crnToWrapper finds them, nil to skip the waitFor
+func listRoutingTables(client *vpcv1.VpcV1, vpc *ResourceInstanceWrapper, crnToWrapper map[string]*ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	vpcid := vpc.crn.vpcId
+	ret := make([]*ResourceInstanceWrapper, 0)
+	var nextHops map[string]string // next hop address to crn, read when the first route is found
+	options := client.NewListVPCRoutingTablesOptions(vpcid)
+	for {
+		collection, _, err := client.ListVPCRoutingTables(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.RoutingTables {
+			routingTable := &collection.RoutingTables[i]
+			parent := vpc
+			if routingTable.IsDefault == nil || !*routingTable.IsDefault {
+				crn := NewFakeCrn("is", vpcid, vpcTypeRoutingTable, *routingTable.ID, vpc.crn.region)
+				ri := NewResourceInstanceWrapper(crn, vpc.ResourceGroupID, routingTable.Name)
+				ri.operations = &VpcRoutingTableOperations{name: vpcString(routingTable.Name), vpcid: vpcid}
+				ri.resource = routingTable
+				ri.parent = vpc
+				ret = append(ret, ri)
+				parent = ri
+				for _, subnet := range routingTable.Subnets {
+					if subnetRi, ok := crnToWrapper[vpcString(subnet.CRN)]; ok {
+						subnetRi.waitFor = append(subnetRi.waitFor, ri)
+					}
+				}
+			}
+			routes, err := listRoutes(client, vpc, parent, *routingTable.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(routes) > 0 && crnToWrapper != nil && nextHops == nil {
+				if nextHops, err = listNextHops(client, vpcid); err != nil {
+					return nil, err
+				}
+			}
+			for _, route := range routes {
+				if target, ok := crnToWrapper[nextHops[routeNextHopAddress(route)]]; ok {
+					target.waitFor = append(target.waitFor, route)
+				}
+			}
+			ret = append(ret, routes...)
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+// routeNextHopAddress is the ip address of the next hop of the route, "" if the next hop is not an address
+func routeNextHopAddress(route *ResourceInstanceWrapper) string {
+	if vpcRoute, ok := route.resource.(*vpcv1.Route); ok {
+		if nextHop, ok := vpcRoute.NextHop.(*vpcv1.RouteNextHop); ok {
+			return vpcString(nextHop.Address)
+		}
+	}
+	return ""
+}
+
+// listNextHops returns the crn of the instance or endpoint gateway (VPE) for each ip address in the vpc, the
+// addresses that can be the next hop of a route
+func listNextHops(client *vpcv1.VpcV1, vpcid string) (map[string]string, error) {
+	ret := make(map[string]string)
+	add := func(ip *vpcv1.ReservedIPReference, crn *string) {
+		if ip != nil && ip.Address != nil && crn != nil {
+			ret[*ip.Address] = *crn
+		}
+	}
+	instanceOptions := client.NewListInstancesOptions().SetVPCID(vpcid)
+	for {
+		collection, _, err := client.ListInstances(instanceOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range collection.Instances {
+			for _, networkInterface := range instance.NetworkInterfaces {
+				add(networkInterface.PrimaryIP, instance.CRN)
+			}
+			for _, networkAttachment := range instance.NetworkAttachments {
+				add(networkAttachment.PrimaryIP, instance.CRN)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			break
+		}
+		instanceOptions.SetStart(*start)
+	}
+	gatewayOptions := client.NewListEndpointGatewaysOptions().SetVPCID(vpcid)
+	for {
+		collection, _, err := client.ListEndpointGateways(gatewayOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, gateway := range collection.EndpointGateways {
+			for i := range gateway.Ips {
+				add(&gateway.Ips[i], gateway.CRN)
+			}
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		gatewayOptions.SetStart(*start)
+	}
+}
+
+// listRoutes returns the routes created by the user, routes created by a service like a vpn server are deleted with the service
+func listRoutes(client *vpcv1.VpcV1, vpc, parent *ResourceInstanceWrapper, routingTableID string) ([]*ResourceInstanceWrapper, error) {
+	vpcid := vpc.crn.vpcId
+	ret := make([]*ResourceInstanceWrapper, 0)
+	options := client.NewListVPCRoutingTableRoutesOptions(vpcid, routingTableID)
+	for {
+		collection, _, err := client.ListVPCRoutingTableRoutes(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.Routes {
+			route := &collection.Routes[i]
+			if route.Creator != nil || vpcString(route.Origin) == vpcv1.RouteOriginServiceConst {
+				continue
+			}
+			crn := NewFakeCrn("is", vpcid, vpcTypeRoutingTableRoute, *route.ID, vpc.crn.region)
+			ri := NewResourceInstanceWrapper(crn, vpc.ResourceGroupID, route.Name)
+			ri.operations = &VpcRouteOperations{name: vpcString(route.Name), vpcid: vpcid, routingTableID: routingTableID}
+			ri.resource = route
+			ri.parent = parent
+			ret = append(ret, ri)
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
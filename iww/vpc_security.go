@@ -0,0 +1,155 @@
+package iww
+
+// security groups and network acls.  The defaults of a vpc are deleted with the vpc, the custom ones are detached
+// from their targets and subnets and deleted
+
+import (
+	"log"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+// vpcDefaultIDs returns the ids of the default security group and the default network acl of the vpc
+func vpcDefaultIDs(client *vpcv1.VpcV1, vpcid string) (securityGroupID string, networkACLID string, err error) {
+	vpc, _, err := client.GetVPC(client.NewGetVPCOptions(vpcid))
+	if err != nil {
+		return "", "", err
+	}
+	if vpc.DefaultSecurityGroup != nil {
+		securityGroupID = vpcString(vpc.DefaultSecurityGroup.ID)
+	}
+	if vpc.DefaultNetworkACL != nil {
+		networkACLID = vpcString(vpc.DefaultNetworkACL.ID)
+	}
+	return securityGroupID, networkACLID, nil
+}
+
+// vpcDefault is true if the security group or network acl is the default of its vpc, the answer is remembered
+// in isDefault.  The second return is false if it is not known yet
+func vpcDefault(ri *ResourceInstanceWrapper, vpcid string, isDefault **bool) (bool, bool) {
+	if *isDefault != nil {
+		return **isDefault, true
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("vpcDefault, getVpcClient err:", err)
+		return false, false
+	}
+	securityGroupID, networkACLID, err := vpcDefaultIDs(client, vpcid)
+	if err != nil {
+		log.Print("vpcDefault, GetVPC err:", err)
+		return false, false
+	}
+	ret := ri.crn.vpcId == securityGroupID || ri.crn.vpcId == networkACLID
+	*isDefault = &ret
+	return ret, true
+}
+
+// --------------------------------------
+// custom security groups are removed from their targets, like network interfaces and load balancers, then deleted
+type VpcGenericSecurityGroupOperation struct {
+	noDelete  VpcGenericNoDeleteOperation // the default security group is deleted with the vpc
+	isDefault *bool                       // nil until the vpc is checked
+}
+
+func (vpc *VpcGenericSecurityGroupOperation) Vpcid() string {
+	return vpc.noDelete.Vpcid()
+}
+
+func (vpc *VpcGenericSecurityGroupOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.noDelete.Fetch(ri)
+}
+
+// securityGroupTargetIDs returns the ids of the targets of the security group
+func securityGroupTargetIDs(securityGroup *vpcv1.SecurityGroup) []string {
+	ret := make([]string, 0, len(securityGroup.Targets))
+	for _, target := range securityGroup.Targets {
+		if reference, ok := target.(*vpcv1.SecurityGroupTargetReference); ok && reference.ID != nil {
+			ret = append(ret, *reference.ID)
+		}
+	}
+	return ret
+}
+
+func (vpc *VpcGenericSecurityGroupOperation) Destroy(ri *ResourceInstanceWrapper) {
+	isDefault, known := vpcDefault(ri, vpc.Vpcid(), &vpc.isDefault)
+	if !known {
+		return
+	}
+	if isDefault {
+		vpc.noDelete.Destroy(ri)
+		return
+	}
+	securityGroup, ok := ri.resource.(*vpcv1.SecurityGroup)
+	if !ok || len(securityGroup.Targets) == 0 {
+		vpc.noDelete.operations.Destroy(ri)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericSecurityGroupOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	for _, targetID := range securityGroupTargetIDs(securityGroup) {
+		MustGlobalContext().verboseLogger.Print("remove security group target:", targetID, " ", ri.crn.Crn)
+		if _, err := client.DeleteSecurityGroupTargetBinding(client.NewDeleteSecurityGroupTargetBindingOptions(ri.crn.vpcId, targetID)); err != nil {
+			// the only security group of a target can not be removed, the target must be deleted first
+			log.Print("VpcGenericSecurityGroupOperation.Destroy, DeleteSecurityGroupTargetBinding err:", err)
+		}
+	}
+}
+
+func (vpc *VpcGenericSecurityGroupOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.noDelete.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// custom network acls are replaced by the default network acl in their subnets, then deleted
+type VpcGenericNetworkACLOperation struct {
+	noDelete  VpcGenericNoDeleteOperation // the default network acl is deleted with the vpc
+	isDefault *bool                       // nil until the vpc is checked
+}
+
+func (vpc *VpcGenericNetworkACLOperation) Vpcid() string {
+	return vpc.noDelete.Vpcid()
+}
+
+func (vpc *VpcGenericNetworkACLOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.noDelete.Fetch(ri)
+}
+
+func (vpc *VpcGenericNetworkACLOperation) Destroy(ri *ResourceInstanceWrapper) {
+	isDefault, known := vpcDefault(ri, vpc.Vpcid(), &vpc.isDefault)
+	if !known {
+		return
+	}
+	if isDefault {
+		vpc.noDelete.Destroy(ri)
+		return
+	}
+	networkACL, ok := ri.resource.(*vpcv1.NetworkACL)
+	if !ok || len(networkACL.Subnets) == 0 {
+		vpc.noDelete.operations.Destroy(ri)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericNetworkACLOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	_, defaultNetworkACLID, err := vpcDefaultIDs(client, vpc.Vpcid())
+	if err != nil {
+		log.Print("VpcGenericNetworkACLOperation.Destroy, GetVPC err:", err)
+		return
+	}
+	for _, subnet := range networkACL.Subnets {
+		MustGlobalContext().verboseLogger.Print("attach default network acl to subnet:", vpcString(subnet.Name), " ", ri.crn.Crn)
+		if _, _, err := client.ReplaceSubnetNetworkACL(client.NewReplaceSubnetNetworkACLOptions(*subnet.ID, &vpcv1.NetworkACLIdentityByID{ID: &defaultNetworkACLID})); err != nil {
+			log.Print("VpcGenericNetworkACLOperation.Destroy, ReplaceSubnetNetworkACL err:", err)
+		}
+	}
+}
+
+func (vpc *VpcGenericNetworkACLOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.noDelete.FormatInstance(ri, fast)
+}
@@ -0,0 +1,180 @@
+package iww
+
+// file shares and their mount targets.  Mount targets do not have a crn.  The fake crn has the share id in the id
+// and the mount target id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:SHAREID:share-mount-target:MOUNTTARGETID
+
+import (
+	"log"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+const vpcTypeShareMountTarget = "share-mount-target"
+
+type VpcShareMountTargetOperations struct {
+	name  string
+	vpcid string
+}
+
+func (mountTarget *VpcShareMountTargetOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcShareMountTargetOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	target, response, err := client.GetShareMountTarget(client.NewGetShareMountTargetOptions(ri.crn.id, ri.crn.vpcId))
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcShareMountTargetOperations.Fetch, GetShareMountTarget err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = target
+	mountTarget.name = vpcString(target.Name)
+	if target.VPC != nil {
+		mountTarget.vpcid = vpcString(target.VPC.ID)
+	}
+}
+
+func (mountTarget *VpcShareMountTargetOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcShareMountTargetOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	if _, _, err = client.DeleteShareMountTarget(client.NewDeleteShareMountTargetOptions(ri.crn.id, ri.crn.vpcId)); err != nil {
+		log.Print("VpcShareMountTargetOperations.Destroy, DeleteShareMountTarget err:", err)
+	}
+}
+
+func (mountTarget *VpcShareMountTargetOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	name := "--"
+	if mountTarget.name != "" {
+		name = mountTarget.name
+	}
+	return FormatInstance(name, "vpc", *ri.crn)
+}
+
+func (mountTarget *VpcShareMountTargetOperations) Vpcid() string {
+	return mountTarget.vpcid
+}
+
+func newShareMountTarget(share *ResourceInstanceWrapper, target *vpcv1.ShareMountTarget) *ResourceInstanceWrapper {
+	crn := NewFakeCrn("is", share.crn.vpcId, vpcTypeShareMountTarget, *target.ID, share.crn.region)
+	ri := NewResourceInstanceWrapper(crn, share.ResourceGroupID, target.Name)
+	operations := &VpcShareMountTargetOperations{name: vpcString(target.Name)}
+	if target.VPC != nil {
+		operations.vpcid = vpcString(target.VPC.ID)
+	}
+	ri.operations = operations
+	ri.resource = target
+	ri.parent = share
+	return ri
+}
+
+// listShareMountTargets returns the mount targets of the share
+func listShareMountTargets(client *vpcv1.VpcV1, share *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	options := client.NewListShareMountTargetsOptions(share.crn.vpcId)
+	for {
+		collection, _, err := client.ListShareMountTargets(options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range collection.MountTargets {
+			ret = append(ret, newShareMountTarget(share, &collection.MountTargets[i]))
+		}
+		start, err := collection.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		options.SetStart(*start)
+	}
+}
+
+// --------------------------------------
+// shares are deleted after the mount targets are deleted and the replication relationship is split
+type VpcGenericShareOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericShareOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+// shareDestroyStep is the next step in the delete sequence of a share
+const (
+	shareDestroyWait         = "wait"          // share is busy, try again later
+	shareDestroyMountTargets = "mount-targets" // delete the mount targets
+	shareDestroySplitReplica = "split-replica" // this share is a replica, split it from the source
+	shareDestroySplitSource  = "split-source"  // this share is a source, split the replica from it
+	shareDestroyDelete       = "delete"        // delete the share
+)
+
+func shareDestroyStep(share *vpcv1.Share) string {
+	switch vpcString(share.LifecycleState) {
+	case vpcv1.ShareLifecycleStatePendingConst, vpcv1.ShareLifecycleStateUpdatingConst, vpcv1.ShareLifecycleStateWaitingConst, vpcv1.ShareLifecycleStateDeletingConst:
+		return shareDestroyWait
+	}
+	if len(share.MountTargets) > 0 {
+		return shareDestroyMountTargets
+	}
+	switch vpcString(share.ReplicationRole) {
+	case vpcv1.ShareReplicationRoleReplicaConst:
+		return shareDestroySplitReplica
+	case vpcv1.ShareReplicationRoleSourceConst:
+		if share.ReplicaShare != nil {
+			return shareDestroySplitSource
+		}
+	}
+	return shareDestroyDelete
+}
+
+func (vpc *VpcGenericShareOperation) Destroy(ri *ResourceInstanceWrapper) {
+	share, ok := ri.resource.(*vpcv1.Share)
+	if !ok {
+		vpc.operations.Destroy(ri)
+		return
+	}
+	step := shareDestroyStep(share)
+	MustGlobalContext().verboseLogger.Print("share destroy step:", step, " ", ri.crn.Crn)
+	switch step {
+	case shareDestroyWait:
+		return
+	case shareDestroyDelete:
+		vpc.operations.Destroy(ri)
+		return
+	}
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcGenericShareOperation.Destroy, getVpcClient err:", err)
+		return
+	}
+	switch step {
+	case shareDestroyMountTargets:
+		for _, target := range share.MountTargets {
+			if _, _, err := client.DeleteShareMountTarget(client.NewDeleteShareMountTargetOptions(*share.ID, *target.ID)); err != nil {
+				log.Print("VpcGenericShareOperation.Destroy, DeleteShareMountTarget err:", err)
+			}
+		}
+	case shareDestroySplitReplica:
+		if _, err := client.DeleteShareSource(client.NewDeleteShareSourceOptions(*share.ID)); err != nil {
+			log.Print("VpcGenericShareOperation.Destroy, DeleteShareSource err:", err)
+		}
+	case shareDestroySplitSource:
+		if _, err := client.DeleteShareSource(client.NewDeleteShareSourceOptions(*share.ReplicaShare.ID)); err != nil {
+			log.Print("VpcGenericShareOperation.Destroy, DeleteShareSource of replica err:", err)
+		}
+	}
+}
+
+func (vpc *VpcGenericShareOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}
@@ -0,0 +1,119 @@
+package iww
+
+// snapshots, snapshot consistency groups and cross region snapshot copies.  The snapshots of a consistency group
+// are deleted with the group.  A copy in another region is a separate snapshot, ls relates it to its source
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+// snapshots created by a backup policy plan are marked and deleted after the backup policy, otherwise the policy
+// would keep creating them.  Snapshots in a consistency group are deleted by the group, see snapshotWaitFor
+type VpcGenericSnapshotOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericSnapshotOperation) Vpcid() string {
+	return vpc.operations.vpcid
+}
+
+func (vpc *VpcGenericSnapshotOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericSnapshotOperation) Destroy(ri *ResourceInstanceWrapper) {
+	vpc.operations.Destroy(ri)
+}
+
+// snapshotDescription is the backup policy plan, consistency group, source and copies of the snapshot
+func snapshotDescription(ri *ResourceInstanceWrapper) string {
+	ret := []string{"vpc"}
+	if plan := snapshotBackupPolicyPlan(ri); plan != nil {
+		ret = append(ret, "backup:"+firstNonEmpty(vpcString(plan.Name), vpcString(plan.ID)))
+	}
+	snapshot, ok := ri.resource.(*vpcv1.Snapshot)
+	if !ok {
+		return strings.Join(ret, " ")
+	}
+	if group := snapshot.SnapshotConsistencyGroup; group != nil {
+		ret = append(ret, "consistency-group:"+firstNonEmpty(vpcString(group.Name), vpcString(group.ID)))
+	}
+	if source := snapshot.SourceSnapshot; source != nil {
+		ret = append(ret, "copy-of:"+snapshotRemoteRegion(source.Remote, ri.crn.region)+"/"+firstNonEmpty(vpcString(source.Name), vpcString(source.ID)))
+	}
+	if len(snapshot.Copies) > 0 {
+		regions := make([]string, 0, len(snapshot.Copies))
+		for _, snapshotCopy := range snapshot.Copies {
+			regions = append(regions, snapshotRemoteRegion(snapshotCopy.Remote, ri.crn.region))
+		}
+		ret = append(ret, "copies:"+strings.Join(regions, ","))
+	}
+	return strings.Join(ret, " ")
+}
+
+// snapshotRemoteRegion is the region of a source or copy, the remote is nil if it is in the same region
+func snapshotRemoteRegion(remote *vpcv1.SnapshotRemote, region string) string {
+	if remote != nil && remote.Region != nil && remote.Region.Name != nil {
+		return *remote.Region.Name
+	}
+	return region
+}
+
+// snapshotSourceCRN returns the crn of the snapshot this snapshot was copied from, "" if not a copy
+func snapshotSourceCRN(ri *ResourceInstanceWrapper) string {
+	if snapshot, ok := ri.resource.(*vpcv1.Snapshot); ok && snapshot.SourceSnapshot != nil {
+		return vpcString(snapshot.SourceSnapshot.CRN)
+	}
+	return ""
+}
+
+func (vpc *VpcGenericSnapshotOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	if description := snapshotDescription(ri); description != "vpc" {
+		return FormatInstance(firstNonEmpty(vpc.operations.name, "--"), description, *ri.crn)
+	}
+	return vpc.operations.FormatInstance(ri, fast)
+}
+
+// --------------------------------------
+// snapshot consistency groups are deleted as a unit: the group deletes its snapshots
+type VpcGenericSnapshotConsistencyGroupOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericSnapshotConsistencyGroupOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+func (vpc *VpcGenericSnapshotConsistencyGroupOperation) Destroy(ri *ResourceInstanceWrapper) {
+	group, ok := ri.resource.(*vpcv1.SnapshotConsistencyGroup)
+	if ok && len(group.Snapshots) > 0 && (group.DeleteSnapshotsOnDelete == nil || !*group.DeleteSnapshotsOnDelete) {
+		client, err := MustGlobalContext().getVpcClient(ri.crn)
+		if err != nil {
+			log.Print("VpcGenericSnapshotConsistencyGroupOperation.Destroy, getVpcClient err:", err)
+			return
+		}
+		deleteSnapshots := true
+		patch, err := (&vpcv1.SnapshotConsistencyGroupPatch{DeleteSnapshotsOnDelete: &deleteSnapshots}).AsPatch()
+		if err != nil {
+			log.Print("VpcGenericSnapshotConsistencyGroupOperation.Destroy, AsPatch err:", err)
+			return
+		}
+		MustGlobalContext().verboseLogger.Print("delete snapshots with the consistency group:", ri.crn.Crn)
+		if _, _, err = client.UpdateSnapshotConsistencyGroup(client.NewUpdateSnapshotConsistencyGroupOptions(ri.crn.vpcId, patch)); err != nil {
+			log.Print("VpcGenericSnapshotConsistencyGroupOperation.Destroy, UpdateSnapshotConsistencyGroup err:", err)
+		}
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericSnapshotConsistencyGroupOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	if group, ok := ri.resource.(*vpcv1.SnapshotConsistencyGroup); ok {
+		return FormatInstance(firstNonEmpty(vpc.operations.name, "--"), "vpc snapshots:"+strconv.Itoa(len(group.Snapshots)), *ri.crn)
+	}
+	return vpc.operations.FormatInstance(ri, fast)
+}
@@ -0,0 +1,653 @@
+package iww
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/Workiva/go-datastructures/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVpcMetadata(t *testing.T) {
+	assert := assert.New(t)
+	str := func(s string) *string { return &s }
+	crn := "crn:v1:bluemix:public:is:us-south-1:a/111::instance:i"
+	instance := &vpcv1.Instance{
+		ID:            str("i"),
+		Name:          str("name"),
+		CRN:           str(crn),
+		Status:        str("running"),
+		Zone:          &vpcv1.ZoneReference{Name: str("us-south-1")},
+		VPC:           &vpcv1.VPCReference{ID: str("v")},
+		ResourceGroup: &vpcv1.ResourceGroupReference{ID: str("g")},
+	}
+	group := "g"
+	ri := NewResourceInstanceWrapper(NewCrn(crn), &group, nil)
+	_, ok := vpcMetadata(ri)
+	assert.False(ok, "nothing fetched yet")
+
+	ri.resource = instance
+	metadata, ok := vpcMetadata(ri)
+	assert.True(ok)
+	assert.Equal(VpcResourceMetadata{ID: "i", Name: "name", Vpcid: "v", Status: "running", Zone: "us-south-1", CRN: crn, ResourceGroupID: "g", Resource: instance}, metadata)
+
+	_, ok = VpcSubtypeOperationsMap["subnet"].(VpcSubtypeListOperations).Metadata(instance)
+	assert.False(ok, "instance is not a subnet")
+}
+
+func TestVpcList(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		subnet := `{"id":"%s","name":"%s","crn":"crn:%s","resource_group":{"id":"%s"},"vpc":{"id":"v"}}`
+		if r.URL.Query().Get("start") == "" {
+			fmt.Fprintf(w, `{"subnets":[`+subnet+`,`+subnet+`],"next":{"href":"%s/v1/subnets?start=page2"}}`,
+				"s1", "one", "s1", "g", "s2", "two", "s2", "other", "http://"+r.Host)
+		} else {
+			fmt.Fprintf(w, `{"subnets":[`+subnet+`]}`, "s3", "three", "s3", "g")
+		}
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	lister := VpcSubtypeOperationsMap["subnet"].(VpcSubtypeListOperations)
+	metadatas, err := lister.List(client, "")
+	assert.Nil(err)
+	assert.Equal(3, len(metadatas), "all pages")
+
+	metadatas, err = lister.List(client, "g")
+	assert.Nil(err)
+	names := []string{}
+	for _, metadata := range metadatas {
+		names = append(names, metadata.Name)
+		assert.Equal("v", metadata.Vpcid)
+	}
+	assert.Equal([]string{"one", "three"}, names, "only the resource group")
+}
+
+func TestVpcDedicated(t *testing.T) {
+	assert := assert.New(t)
+	operations := func(vpcType string) ResourceInstanceOperations {
+		ops, err := NewVpcOperations(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::" + vpcType + ":id"))
+		assert.Nil(err)
+		return ops
+	}
+	assert.IsType(&VpcGenericBareMetalServerOperation{}, operations("bare-metal-server"))
+	assert.IsType(&VpcGenericDedicatedHostOperation{}, operations("dedicated-host"))
+	assert.IsType(&VpcGenericDedicatedHostGroupOperation{}, operations("dedicated-host-group"))
+	assert.IsType(&VpcGenericOperation{}, operations("placement-group"))
+
+	str := func(s string) *string { return &s }
+	group := "g"
+	hostGroup := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::dedicated-host-group:dg"), &group, nil)
+	host := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::dedicated-host:dh"), &group, nil)
+	host.resource = &vpcv1.DedicatedHost{Group: &vpcv1.DedicatedHostGroupReference{ID: str("dg")}}
+	assert.Equal([]relation{{relationContains, hostGroup, host}}, resourceRelations([]*ResourceInstanceWrapper{hostGroup, host}))
+}
+
+func TestVpcReservedIPs(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.Equal("/v1/subnets/s/reserved_ips", r.URL.Path)
+		fmt.Fprint(w, `{"reserved_ips":[
+			{"id":"r1","name":"bound","owner":"user","target":{"id":"e","resource_type":"endpoint_gateway"}},
+			{"id":"r2","name":"orphan","owner":"user"},
+			{"id":"r3","name":"gateway","owner":"provider"}]}`)
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	ips, err := subnetReservedIPs(client, "s")
+	assert.Nil(err)
+	assert.Equal(2, len(ips), "only user owned")
+	assert.NotNil(ips[0].Target)
+	assert.Nil(ips[1].Target)
+
+	group := "g"
+	subnet := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::subnet:s"), &group, nil)
+	subnet.operations = &VpcGenericSubnetOperation{operations: VpcGenericOperation{vpcid: "v"}}
+	ri := newReservedIP(subnet, ips[1])
+	crn := NewCrn(ri.crn.Crn)
+	assert.Equal("s", crn.id)
+	assert.Equal(vpcTypeReservedIP, crn.vpcType)
+	assert.Equal("r2", crn.vpcId)
+	assert.Equal("v", ri.operations.(VpcResourceInstanceOperations).Vpcid())
+	assert.Equal([]relation{{relationSub, subnet, ri}}, resourceRelations([]*ResourceInstanceWrapper{subnet, ri}))
+}
+
+func TestVpcShareDestroyStep(t *testing.T) {
+	assert := assert.New(t)
+	str := func(s string) *string { return &s }
+	share := func(state, role string, mountTargets int, replica bool) *vpcv1.Share {
+		ret := &vpcv1.Share{ID: str("s"), LifecycleState: str(state), ReplicationRole: str(role)}
+		for i := 0; i < mountTargets; i++ {
+			ret.MountTargets = append(ret.MountTargets, vpcv1.ShareMountTargetReference{ID: str(fmt.Sprint("m", i))})
+		}
+		if replica {
+			ret.ReplicaShare = &vpcv1.ShareReference{ID: str("r")}
+		}
+		return ret
+	}
+	assert.Equal(shareDestroyWait, shareDestroyStep(share("updating", "none", 1, false)))
+	assert.Equal(shareDestroyMountTargets, shareDestroyStep(share("stable", "source", 2, true)))
+	assert.Equal(shareDestroySplitReplica, shareDestroyStep(share("stable", "replica", 0, false)))
+	assert.Equal(shareDestroySplitSource, shareDestroyStep(share("stable", "source", 0, true)))
+	assert.Equal(shareDestroyDelete, shareDestroyStep(share("stable", "source", 0, false)))
+	assert.Equal(shareDestroyDelete, shareDestroyStep(share("failed", "none", 0, false)))
+
+	group := "g"
+	shareRi := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::share:s"), &group, nil)
+	mountTarget := newShareMountTarget(shareRi, &vpcv1.ShareMountTarget{ID: str("m"), Name: str("mt"), VPC: &vpcv1.VPCReference{ID: str("v")}})
+	assert.Equal("s", mountTarget.crn.id)
+	assert.Equal("m", mountTarget.crn.vpcId)
+	assert.Equal("v", vpcidOf(mountTarget))
+	assert.Equal(shareRi, mountTarget.parent)
+}
+
+func TestVpcBackup(t *testing.T) {
+	assert := assert.New(t)
+	str := func(s string) *string { return &s }
+	assert.Equal("p", backupPolicyIDFromHref("https://us-south.iaas.cloud.ibm.com/v1/backup_policies/p/plans/pp"))
+	assert.Equal("", backupPolicyIDFromHref("https://us-south.iaas.cloud.ibm.com/v1/snapshots/s"))
+
+	crn := NewCrn("crn:v1:bluemix:public:is:us-south:a/111::snapshot:s")
+	operations, err := NewVpcOperations(crn)
+	assert.Nil(err)
+	snapshotOperations := operations.(*VpcGenericSnapshotOperation)
+	snapshotOperations.operations.name = "snap"
+	group := "g"
+	ri := NewResourceInstanceWrapper(crn, &group, nil)
+	ri.operations = operations
+	assert.Equal("is snapshot snap vpc "+crn.Crn, ri.FormatInstance(false))
+	ri.resource = &vpcv1.Snapshot{BackupPolicyPlan: &vpcv1.BackupPolicyPlanReference{ID: str("pp"), Name: str("daily")}}
+	assert.Equal("is snapshot snap vpc backup:daily "+crn.Crn, ri.FormatInstance(false))
+
+	operations, err = NewVpcOperations(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::backup-policy:p"))
+	assert.Nil(err)
+	assert.IsType(&VpcGenericBackupPolicyOperation{}, operations)
+}
+
+func TestVpcVPN(t *testing.T) {
+	assert := assert.New(t)
+	str := func(s string) *string { return &s }
+	for _, vpcType := range []string{"ikepolicy", "ipsecpolicy"} {
+		operations, err := NewVpcOperations(NewFakeCrn("is", "", vpcType, "p", "us-south"))
+		assert.Nil(err)
+		assert.IsType(&VpcGenericVPNPolicyOperation{}, operations, vpcType)
+	}
+	group := "g"
+	policy := NewResourceInstanceWrapper(NewFakeCrn("is", "", "ipsecpolicy", "p", "us-south"), &group, nil)
+	assert.Equal(0, vpnPolicyConnections(policy))
+	policy.resource = &vpcv1.IPsecPolicy{Connections: []vpcv1.VPNGatewayConnectionReference{{ID: str("c")}}}
+	assert.Equal(1, vpnPolicyConnections(policy))
+
+	server := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::vpn-server:vs"), &group, nil)
+	server.operations = &VpcGenericOperation{vpcid: "v"}
+	route := newVPNServerChild(server, vpcTypeVPNServerRoute, "r", "route", &vpcv1.VPNServerRoute{})
+	assert.Equal("vs", route.crn.id)
+	assert.Equal("r", route.crn.vpcId)
+	assert.Equal("v", vpcidOf(route))
+	assert.Equal("is vpn-server-route route vpc "+route.crn.Crn, route.FormatInstance(false))
+}
+
+func TestVpcRoutingTables(t *testing.T) {
+	assert := assert.New(t)
+	subnetCrn := "crn:v1:bluemix:public:is:us-south-1:a/111::subnet:s"
+	instanceCrn := "crn:v1:bluemix:public:is:us-south-1:a/111::instance:i"
+	vpeCrn := "crn:v1:bluemix:public:is:us-south:a/111::endpoint-gateway:e"
+	nextHopLists := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/vpcs/v/routing_tables":
+			fmt.Fprint(w, `{"routing_tables":[{"id":"default","name":"d","is_default":true},{"id":"custom","name":"c","is_default":false,"subnets":[{"crn":"`+subnetCrn+`","id":"s"}]}]}`)
+		case "/v1/vpcs/v/routing_tables/default/routes":
+			fmt.Fprint(w, `{"routes":[{"id":"r1","name":"user","origin":"user","next_hop":{"address":"10.0.0.9"}},{"id":"r2","name":"vpn","origin":"service"}]}`)
+		case "/v1/vpcs/v/routing_tables/custom/routes":
+			fmt.Fprint(w, `{"routes":[{"id":"r3","name":"hop","origin":"user","next_hop":{"address":"10.0.0.4"}}]}`)
+		case "/v1/instances":
+			nextHopLists++
+			assert.Equal("v", r.URL.Query().Get("vpc.id"))
+			fmt.Fprint(w, `{"instances":[{"crn":"`+instanceCrn+`","network_attachments":[{"primary_ip":{"address":"10.0.0.4"}}]}]}`)
+		case "/v1/endpoint_gateways":
+			fmt.Fprint(w, `{"endpoint_gateways":[{"crn":"`+vpeCrn+`","ips":[{"address":"10.0.0.9"}]}]}`)
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	group := "g"
+	vpc := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::vpc:v"), &group, nil)
+	crnToWrapper := make(map[string]*ResourceInstanceWrapper)
+	for _, crn := range []string{subnetCrn, instanceCrn, vpeCrn} {
+		crnToWrapper[crn] = NewResourceInstanceWrapper(NewCrn(crn), &group, nil)
+	}
+	ris, err := listRoutingTables(client, vpc, crnToWrapper)
+	assert.Nil(err)
+	names := []string{}
+	for _, ri := range ris {
+		names = append(names, ri.crn.vpcType+" "+*ri.Name+" "+ri.parent.crn.vpcId)
+		assert.Equal("v", vpcidOf(ri))
+	}
+	assert.Equal([]string{"routing-table-route user v", "routing-table c v", "routing-table-route hop custom"}, names)
+
+	// the next hops and the attached subnet wait for the routes and the routing table
+	assert.Equal([]*ResourceInstanceWrapper{ris[0]}, crnToWrapper[vpeCrn].waitFor)
+	assert.Equal([]*ResourceInstanceWrapper{ris[2]}, crnToWrapper[instanceCrn].waitFor)
+	assert.Equal([]*ResourceInstanceWrapper{ris[1]}, crnToWrapper[subnetCrn].waitFor)
+
+	// without the wrappers the next hops are not listed
+	ris, err = listRoutingTables(client, vpc, nil)
+	assert.Nil(err)
+	assert.Len(ris, 3)
+	assert.Equal(1, nextHopLists)
+	assert.True(routingTableIngress(&vpcv1.RoutingTable{RouteTransitGatewayIngress: core.BoolPtr(true)}))
+	assert.False(routingTableIngress(&vpcv1.RoutingTable{RouteTransitGatewayIngress: core.BoolPtr(false)}))
+}
+
+// testRmOperations is a resource that is deleted by Destroy, order records the crns in the order they are destroyed
+type testRmOperations struct {
+	destroyed bool
+	order     *[]string
+}
+
+func (o *testRmOperations) Fetch(ri *ResourceInstanceWrapper) {
+	ri.state = SIStateExists
+	if o.destroyed {
+		ri.state = SIStateDeleted
+	}
+}
+
+func (o *testRmOperations) Destroy(ri *ResourceInstanceWrapper) {
+	o.destroyed = true
+	*o.order = append(*o.order, ri.crn.vpcType)
+}
+
+func (o *testRmOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return ri.crn.Crn
+}
+
+// testFinder finds the resources that have not been destroyed
+type testFinder struct {
+	ris []*ResourceInstanceWrapper
+}
+
+func (finder testFinder) Find([]*ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	for _, ri := range finder.ris {
+		if !ri.operations.(*testRmOperations).destroyed {
+			ret = append(ret, NewResourceInstanceWrapper(ri.crn, ri.ResourceGroupID, nil))
+		}
+	}
+	return ret, nil
+}
+
+func TestVpcRmRouteBeforeNextHop(t *testing.T) {
+	assert := assert.New(t)
+	savedContext, savedFinders, savedInterval := GlobalContext, resourceFinders, rmPollInterval
+	defer func() { GlobalContext, resourceFinders, rmPollInterval = savedContext, savedFinders, savedInterval }()
+	GlobalContext = &Context{verboseLogger: log.New(io.Discard, "", 0), progressBarWrapper: newSilentProgressBarWrapper()}
+	rmPollInterval = 0
+
+	group := "g"
+	order := []string{}
+	instance := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::instance:i"), &group, nil)
+	route := NewResourceInstanceWrapper(NewFakeCrn("is", "v", vpcTypeRoutingTableRoute, "r", "us-south"), &group, nil)
+	// the instance is first, it waits for the route that has it as the next hop, see listRoutingTables
+	instance.waitFor = []*ResourceInstanceWrapper{route}
+	ris := []*ResourceInstanceWrapper{instance, route}
+	for _, ri := range ris {
+		ri.operations = &testRmOperations{order: &order}
+		ri.Fetch()
+	}
+	resourceFinders = []ResourceFinder{testFinder{ris}}
+	err := rmServiceInstances(ris, func(status string, si *ResourceInstanceWrapper) {
+		if si == instance && status == RmStatusDestroying {
+			assert.Equal(SIStateDeleted, route.state, "instance destroyed while the route exists")
+		}
+	})
+	assert.Nil(err)
+	assert.Equal([]string{vpcTypeRoutingTableRoute, "instance"}, order)
+}
+
+func TestPruneResourcesThatDoNotExist(t *testing.T) {
+	assert := assert.New(t)
+	savedContext, savedFinders := GlobalContext, resourceFinders
+	defer func() { GlobalContext, resourceFinders = savedContext, savedFinders }()
+	GlobalContext = &Context{verboseLogger: log.New(io.Discard, "", 0), progressBarWrapper: newSilentProgressBarWrapper()}
+
+	group := "g"
+	order := []string{}
+	instance := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south-1:a/111::instance:i"), &group, nil)
+	route := NewResourceInstanceWrapper(NewFakeCrn("is", "v", vpcTypeRoutingTableRoute, "r", "us-south"), &group, nil)
+	instance.waitFor = []*ResourceInstanceWrapper{route}
+	ris := []*ResourceInstanceWrapper{instance, route}
+	for _, ri := range ris {
+		ri.operations = &testRmOperations{order: &order}
+		ri.Fetch()
+	}
+	removing := map[*ResourceInstanceWrapper]bool{instance: true, route: true}
+	assert.Equal(route, instance.waiting(removing))
+
+	// the route is no longer listed while it is being destroyed
+	route.operations.(*testRmOperations).destroyed = true
+	route.state = SIStateDestroying
+	resourceFinders = []ResourceFinder{testFinder{ris}}
+	assert.Equal([]*ResourceInstanceWrapper{instance}, pruneResourcesThatDoNotExist(ris))
+	assert.Equal(SIStateDeleted, route.state)
+	assert.Nil(instance.waiting(removing))
+	assert.False(GlobalContext.pruning)
+}
+
+func TestVpcPrivatePath(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/private_path_service_gateways/p/endpoint_gateway_bindings":
+			fmt.Fprint(w, `{"endpoint_gateway_bindings":[{"id":"b1","status":"permitted","account":{"id":"222"}},{"id":"b2","status":"denied","account":{"id":"333"}},{"id":"b3","status":"pending","account":{"id":"444"}}]}`)
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	group := "g"
+	gateway := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::private-path-service-gateway:p"), &group, nil)
+	gateway.operations, err = NewVpcOperations(gateway.crn)
+	assert.Nil(err)
+	gateway.resource = &vpcv1.PrivatePathServiceGateway{ID: core.StringPtr("p"), VPC: &vpcv1.VPCReference{ID: core.StringPtr("v")}}
+	assert.Equal("v", vpcidOf(gateway)) // from the listed resource before a fetch
+	ris, err := listPrivatePathBindings(client, gateway)
+	assert.Nil(err)
+	names := []string{}
+	for _, ri := range ris {
+		names = append(names, ri.crn.vpcType+" "+*ri.Name+" "+ri.crn.vpcId)
+		assert.Equal(gateway, ri.parent)
+		assert.Equal("v", vpcidOf(ri))
+	}
+	assert.Equal([]string{"private-path-service-gateway-binding 222 b1", "private-path-service-gateway-binding 444 b3"}, names)
+
+	target := &vpcv1.VirtualNetworkInterfaceTarget{ID: core.StringPtr("mt")}
+	assert.True(vpcDeletedWithTarget(&vpcv1.VirtualNetworkInterface{Target: target, AutoDelete: core.BoolPtr(true)}))
+	assert.False(vpcDeletedWithTarget(&vpcv1.VirtualNetworkInterface{Target: target, AutoDelete: core.BoolPtr(false)}))
+	assert.False(vpcDeletedWithTarget(&vpcv1.VirtualNetworkInterface{AutoDelete: core.BoolPtr(true)}))
+	metadata, ok := VpcSubtypeOperationsMap["virtual-network-interface"].(VpcSubtypeListOperations).Metadata(&vpcv1.VirtualNetworkInterface{ID: core.StringPtr("n"), VPC: &vpcv1.VPCReference{ID: core.StringPtr("v")}, Zone: &vpcv1.ZoneReference{Name: core.StringPtr("us-south-1")}})
+	assert.True(ok)
+	assert.Equal("v", metadata.Vpcid)
+	assert.Equal("us-south-1", metadata.Zone)
+}
+
+func TestVpcSecurity(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/vpcs/v":
+			fmt.Fprint(w, `{"id":"v","default_security_group":{"id":"sg"},"default_network_acl":{"id":"acl"}}`)
+		case "/v1/security_groups/custom":
+			fmt.Fprint(w, `{"id":"custom","targets":[{"id":"nic","resource_type":"network_interface"},{"id":"lb","resource_type":"load_balancer"}]}`)
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	securityGroupID, networkACLID, err := vpcDefaultIDs(client, "v")
+	assert.Nil(err)
+	assert.Equal("sg", securityGroupID)
+	assert.Equal("acl", networkACLID)
+
+	securityGroup, _, err := client.GetSecurityGroup(client.NewGetSecurityGroupOptions("custom"))
+	assert.Nil(err)
+	assert.Equal([]string{"nic", "lb"}, securityGroupTargetIDs(securityGroup))
+
+	// the remembered answer does not need the vpc
+	isDefault := true
+	remembered := &isDefault
+	ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::security-group:sg"), nil, nil)
+	answer, known := vpcDefault(ri, "v", &remembered)
+	assert.True(answer)
+	assert.True(known)
+}
+
+func TestVpcInstanceDestroyStep(t *testing.T) {
+	assert := assert.New(t)
+	attachment := func(name, attachmentType, status string, autoDelete bool) vpcv1.VolumeAttachment {
+		return vpcv1.VolumeAttachment{ID: core.StringPtr(name), Type: &attachmentType, Status: &status, DeleteVolumeOnInstanceDelete: &autoDelete, Volume: &vpcv1.VolumeReferenceVolumeAttachmentContext{Name: core.StringPtr(name)}}
+	}
+	boot := attachment("boot", "boot", "attached", true)
+	scratch := attachment("scratch", "data", "attached", true)
+	keep := attachment("keep", "data", "attached", false)
+	detaching := attachment("keep", "data", "detaching", false)
+	running := &vpcv1.Instance{Status: core.StringPtr("running")}
+	stopped := &vpcv1.Instance{Status: core.StringPtr("stopped")}
+
+	assert.Equal(instanceDestroyWait, instanceDestroyStep(&vpcv1.Instance{Status: core.StringPtr("stopping")}, nil, true))
+	assert.Equal(instanceDestroyStop, instanceDestroyStep(running, nil, true))
+	assert.Equal(instanceDestroyDelete, instanceDestroyStep(running, []vpcv1.VolumeAttachment{boot, scratch}, false))
+	assert.Equal(instanceDestroyDetach, instanceDestroyStep(stopped, []vpcv1.VolumeAttachment{boot, scratch, keep}, true))
+	assert.Equal(instanceDestroyWait, instanceDestroyStep(stopped, []vpcv1.VolumeAttachment{boot, detaching}, true))
+
+	ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::instance:i"), nil, nil)
+	ri.operations = &VpcGenericInstanceOperation{attachments: []vpcv1.VolumeAttachment{boot, scratch, keep}}
+	assert.Equal([]string{"boot", "scratch"}, instanceAutoDeleteVolumes(ri))
+	assert.Contains(ri.operations.FormatInstance(ri, true), "auto-delete:boot,scratch")
+}
+
+func TestVpcLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	saved := GlobalContext
+	GlobalContext = &Context{verboseLogger: log.New(io.Discard, "", 0)}
+	defer func() { GlobalContext = saved }()
+
+	operation := &VpcGenericOperation{name: "lb"}
+	ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::load-balancer:l"), nil, nil)
+	for status, state := range map[string]int{"active": SIStateExists, "update_pending": SIStateDestroying, "deleting": SIStateDestroying, "failed": SIStateExists} {
+		ri.state = SIStateExists
+		operation.lifecycle(ri, status)
+		assert.Equal(state, ri.state, status)
+	}
+	assert.True(operation.failedReported)
+}
+
+func TestVpcImage(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/instances":
+			fmt.Fprint(w, `{"instances":[{"id":"i1","name":"uses","image":{"id":"img"}},{"id":"i2","name":"other","image":{"id":"stock"}}]}`)
+		case "/v1/instance/templates":
+			fmt.Fprint(w, `{"templates":[{"id":"t1","name":"template","image":{"id":"img"}}]}`)
+		case "/v1/snapshots":
+			fmt.Fprint(w, `{"snapshots":[{"id":"s1","name":"snap","source_image":{"id":"img"}}]}`)
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	users, err := listImageUsers(client)
+	assert.Nil(err)
+	labels := []string{}
+	for _, user := range users {
+		labels = append(labels, user.imageID+" "+user.label)
+	}
+	assert.Equal([]string{"img instance:uses", "stock instance:other", "img instance-template:template", "img snapshot:snap"}, labels)
+
+	assert.True(imageRetireNeeded("available", false))
+	assert.False(imageRetireNeeded("deprecated", false))
+	assert.True(imageRetireNeeded("deprecated", true))
+	assert.False(imageRetireNeeded("obsolete", true))
+}
+
+func TestVpcSnapshotCopies(t *testing.T) {
+	assert := assert.New(t)
+	group := "g"
+	snapshot := func(region, id string, resource *vpcv1.Snapshot) *ResourceInstanceWrapper {
+		ri := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:"+region+":a/111::snapshot:"+id), &group, nil)
+		ri.operations = &VpcGenericSnapshotOperation{operations: VpcGenericOperation{name: id}}
+		ri.resource = resource
+		return ri
+	}
+	consistencyGroup := NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::snapshot-consistency-group:cg"), &group, nil)
+	consistencyGroup.operations = &VpcGenericSnapshotConsistencyGroupOperation{operations: VpcGenericOperation{name: "cg"}}
+	consistencyGroup.resource = &vpcv1.SnapshotConsistencyGroup{Snapshots: []vpcv1.SnapshotReference{{ID: core.StringPtr("source")}}}
+	source := snapshot("us-south", "source", &vpcv1.Snapshot{
+		SnapshotConsistencyGroup: &vpcv1.SnapshotConsistencyGroupReference{ID: core.StringPtr("cg"), Name: core.StringPtr("cg")},
+		Copies:                   []vpcv1.SnapshotCopiesItem{{ID: core.StringPtr("copy"), Remote: &vpcv1.SnapshotRemote{Region: &vpcv1.RegionReference{Name: core.StringPtr("eu-de")}}}},
+	})
+	copied := snapshot("eu-de", "copy", &vpcv1.Snapshot{
+		SourceSnapshot: &vpcv1.SnapshotSourceSnapshot{CRN: core.StringPtr(source.crn.Crn), Name: core.StringPtr("source"), Remote: &vpcv1.SnapshotRemote{Region: &vpcv1.RegionReference{Name: core.StringPtr("us-south")}}},
+	})
+
+	assert.Equal("vpc consistency-group:cg copies:eu-de", snapshotDescription(source))
+	assert.Equal("vpc copy-of:us-south/source", snapshotDescription(copied))
+	assert.Contains(consistencyGroup.FormatInstance(true), "vpc snapshots:1")
+	assert.Equal([]relation{
+		{relationContains, consistencyGroup, source},
+		{relationCopy, source, copied},
+	}, resourceRelations([]*ResourceInstanceWrapper{consistencyGroup, source, copied}))
+}
+
+func TestVpcSnapshotWaitFor(t *testing.T) {
+	assert := assert.New(t)
+	snapshotLists := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/snapshots":
+			snapshotLists++
+			plan := `{"id":"pp","href":"https://us-south.iaas.cloud.ibm.com/v1/backup_policies/bp/plans/pp","name":"plan"}`
+			fmt.Fprint(w, `{"snapshots":[{"id":"s1","backup_policy_plan":`+plan+`},{"id":"s2","snapshot_consistency_group":{"id":"cg"}},{"id":"s3"}]}`)
+		case "/v1/snapshot_consistency_groups":
+			fmt.Fprint(w, `{"snapshot_consistency_groups":[{"id":"cg","backup_policy_plan":{"id":"pp","href":"https://us-south.iaas.cloud.ibm.com/v1/backup_policies/bp/plans/pp"}}]}`)
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{verboseLogger: log.New(io.Discard, "", 0), authenticator: &core.NoAuthAuthenticator{}, endpoints: map[string]string{"vpc": server.URL + "/v1"}}
+	defer func() { GlobalContext = saved }()
+
+	wrapper := func(vpcType, id string) *ResourceInstanceWrapper {
+		return NewResourceInstanceWrapper(NewCrn("crn:v1:bluemix:public:is:us-south:a/111::"+vpcType+":"+id), nil, nil)
+	}
+	policy, group := wrapper("backup-policy", "bp"), wrapper("snapshot-consistency-group", "cg")
+	s1, s2, s3 := wrapper("snapshot", "s1"), wrapper("snapshot", "s2"), wrapper("snapshot", "s3")
+	snapshotWaitFor([]*ResourceInstanceWrapper{policy, group, s1, s2, s3})
+	assert.Equal([]*ResourceInstanceWrapper{policy}, s1.waitFor)
+	assert.Equal([]*ResourceInstanceWrapper{group}, s2.waitFor)
+	assert.Empty(s3.waitFor)
+	assert.Equal([]*ResourceInstanceWrapper{policy}, group.waitFor)
+
+	// the policy is not being removed, the snapshot is deleted right away
+	assert.Nil(s1.waiting(map[*ResourceInstanceWrapper]bool{s1: true}))
+	assert.Equal(policy, s1.waiting(map[*ResourceInstanceWrapper]bool{s1: true, policy: true}))
+
+	// the snapshots are not listed again while removing
+	assert.Equal(1, snapshotLists)
+	GlobalContext.pruning = true
+	s1 = wrapper("snapshot", "s1")
+	vpcWaitFor([]*ResourceInstanceWrapper{policy, s1})
+	assert.Equal(1, snapshotLists)
+	assert.Empty(s1.waitFor)
+}
+
+func TestVpcListIPsecPolicies(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// no resource group and a next link that can not be parsed
+		fmt.Fprint(w, `{"ipsec_policies":[{"id":"p","name":"policy"}],"next":{"href":"%zz"}}`)
+	}))
+	defer server.Close()
+	client, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{Authenticator: &core.NoAuthAuthenticator{}, URL: server.URL + "/v1"})
+	assert.Nil(err)
+
+	list := set.New()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	listIPsecPolicies(list, client, &wg)
+	var policy *ResourceInstanceWrapper
+	errs := 0
+	for _, item := range list.Flatten() {
+		rie := item.(*resourceInstanceWrapperErr)
+		if rie.err != nil {
+			errs++
+		} else {
+			policy = rie.ri
+		}
+	}
+	assert.Equal(1, errs)
+	if assert.NotNil(policy) {
+		assert.Equal("", *policy.ResourceGroupID)
+	}
+}
+
+func TestVpcImageWaitFor(t *testing.T) {
+	assert := assert.New(t)
+	crn := func(vpcType, id string) string {
+		return "crn:v1:bluemix:public:is:us-south:a/111::" + vpcType + ":" + id
+	}
+	instanceLists := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/instances":
+			instanceLists++
+			fmt.Fprintf(w, `{"instances":[{"id":"i1","crn":"%s","name":"removed","image":{"id":"img"}},{"id":"i2","crn":"%s","name":"kept","image":{"id":"img"}}]}`, crn("instance", "i1"), crn("instance", "i2"))
+		case "/v1/instance/templates":
+			fmt.Fprint(w, `{"templates":[]}`)
+		case "/v1/snapshots":
+			assert.Equal("", r.URL.Query().Get("source_image.id"))
+			fmt.Fprintf(w, `{"snapshots":[{"id":"s1","crn":"%s","name":"snap","source_image":{"id":"img"}}]}`, crn("snapshot", "s1"))
+		default:
+			t.Error("unexpected path:", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	saved := GlobalContext
+	GlobalContext = &Context{verboseLogger: log.New(io.Discard, "", 0), authenticator: &core.NoAuthAuthenticator{}, endpoints: map[string]string{"vpc": server.URL + "/v1"}}
+	defer func() { GlobalContext = saved }()
+
+	image := NewResourceInstanceWrapper(NewCrn(crn("image", "img")), nil, nil)
+	unused := NewResourceInstanceWrapper(NewCrn(crn("image", "unused")), nil, nil)
+	for _, ri := range []*ResourceInstanceWrapper{image, unused} {
+		operations, err := NewVpcOperations(ri.crn)
+		assert.Nil(err)
+		ri.operations = operations
+	}
+	instance := NewResourceInstanceWrapper(NewCrn(crn("instance", "i1")), nil, nil)
+	snapshot := NewResourceInstanceWrapper(NewCrn(crn("snapshot", "s1")), nil, nil)
+	imageWaitFor([]*ResourceInstanceWrapper{image, unused, instance, snapshot})
+	// the users are listed once for the region and stored on the image
+	assert.Equal(1, instanceLists)
+	assert.Equal([]string{"instance:removed", "instance:kept", "snapshot:snap"}, vpcImageUsers(image))
+	assert.Empty(vpcImageUsers(unused))
+	// the instance that is not listed is not waited for
+	assert.Equal([]*ResourceInstanceWrapper{instance, snapshot}, image.waitFor)
+	assert.Equal(snapshot, image.waiting(map[*ResourceInstanceWrapper]bool{image: true, snapshot: true}))
+	assert.Nil(image.waiting(map[*ResourceInstanceWrapper]bool{image: true}))
+}
@@ -0,0 +1,224 @@
+package iww
+
+// client to site vpn servers with their routes and clients, and the ipsec policies of the site to site vpn gateway
+// connections.  Routes and clients do not have a crn.  The fake crn has the vpn server id in the id and the route or
+// client id in the vpcId:
+// crn:v1:bluemix:public:is:us-south:a/ACCOUNT:VPNSERVERID:vpn-server-route:ROUTEID
+
+import (
+	"log"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/Workiva/go-datastructures/set"
+)
+
+const (
+	vpcTypeVPNServerRoute  = "vpn-server-route"
+	vpcTypeVPNServerClient = "vpn-server-client"
+)
+
+// VpcVPNServerChildOperations are the operations for a route or a client of a vpn server
+type VpcVPNServerChildOperations struct {
+	name  string
+	vpcid string // of the vpn server
+}
+
+// getVPNServerChild returns the name and the route or client
+func getVPNServerChild(client *vpcv1.VpcV1, crn *Crn) (string, interface{}, *core.DetailedResponse, error) {
+	if crn.vpcType == vpcTypeVPNServerRoute {
+		route, response, err := client.GetVPNServerRoute(client.NewGetVPNServerRouteOptions(crn.id, crn.vpcId))
+		if err != nil {
+			return "", nil, response, err
+		}
+		return vpcString(route.Name), route, response, nil
+	}
+	vpnClient, response, err := client.GetVPNServerClient(client.NewGetVPNServerClientOptions(crn.id, crn.vpcId))
+	if err != nil {
+		return "", nil, response, err
+	}
+	return firstNonEmpty(vpcString(vpnClient.CommonName), vpcString(vpnClient.Username), vpcString(vpnClient.ID)), vpnClient, response, nil
+}
+
+func (child *VpcVPNServerChildOperations) Fetch(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcVPNServerChildOperations.Fetch, getVpcClient err:", err)
+		return
+	}
+	name, resource, response, err := getVPNServerChild(client, ri.crn)
+	if err != nil {
+		if response != nil && response.StatusCode == 404 {
+			ri.state = SIStateDeleted
+		} else {
+			log.Print("VpcVPNServerChildOperations.Fetch, get ", ri.crn.vpcType, " err:", err)
+		}
+		return
+	}
+	ri.state = SIStateExists
+	ri.resource = resource
+	child.name = name
+}
+
+func (child *VpcVPNServerChildOperations) Destroy(ri *ResourceInstanceWrapper) {
+	client, err := MustGlobalContext().getVpcClient(ri.crn)
+	if err != nil {
+		log.Print("VpcVPNServerChildOperations.Destroy, getVpcClient err:", err)
+		return
+	}
+	if ri.crn.vpcType == vpcTypeVPNServerRoute {
+		_, err = client.DeleteVPNServerRoute(client.NewDeleteVPNServerRouteOptions(ri.crn.id, ri.crn.vpcId))
+	} else {
+		_, err = client.DeleteVPNServerClient(client.NewDeleteVPNServerClientOptions(ri.crn.id, ri.crn.vpcId))
+	}
+	if err != nil {
+		log.Print("VpcVPNServerChildOperations.Destroy, delete ", ri.crn.vpcType, " err:", err)
+	}
+}
+
+func (child *VpcVPNServerChildOperations) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return FormatInstance(firstNonEmpty(child.name, "--"), "vpc", *ri.crn)
+}
+
+func (child *VpcVPNServerChildOperations) Vpcid() string {
+	return child.vpcid
+}
+
+func newVPNServerChild(server *ResourceInstanceWrapper, vpcType, id, name string, resource interface{}) *ResourceInstanceWrapper {
+	crn := NewFakeCrn("is", server.crn.vpcId, vpcType, id, server.crn.region)
+	ri := NewResourceInstanceWrapper(crn, server.ResourceGroupID, &name)
+	ri.operations = &VpcVPNServerChildOperations{name: name, vpcid: vpcidOf(server)}
+	ri.resource = resource
+	ri.parent = server
+	return ri
+}
+
+// listVPNServerChildren returns the routes and the clients of the vpn server
+func listVPNServerChildren(client *vpcv1.VpcV1, server *ResourceInstanceWrapper) ([]*ResourceInstanceWrapper, error) {
+	ret := make([]*ResourceInstanceWrapper, 0)
+	routeOptions := client.NewListVPNServerRoutesOptions(server.crn.vpcId)
+	for {
+		routes, _, err := client.ListVPNServerRoutes(routeOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range routes.Routes {
+			route := &routes.Routes[i]
+			ret = append(ret, newVPNServerChild(server, vpcTypeVPNServerRoute, *route.ID, vpcString(route.Name), route))
+		}
+		start, err := routes.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			break
+		}
+		routeOptions.SetStart(*start)
+	}
+	clientOptions := client.NewListVPNServerClientsOptions(server.crn.vpcId)
+	for {
+		clients, _, err := client.ListVPNServerClients(clientOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range clients.Clients {
+			vpnClient := &clients.Clients[i]
+			name := firstNonEmpty(vpcString(vpnClient.CommonName), vpcString(vpnClient.Username), *vpnClient.ID)
+			ret = append(ret, newVPNServerChild(server, vpcTypeVPNServerClient, *vpnClient.ID, name, vpnClient))
+		}
+		start, err := clients.GetNextStart()
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			return ret, nil
+		}
+		clientOptions.SetStart(*start)
+	}
+}
+
+// --------------------------------------
+// ipsec policies, like ike policies, are regional and do not have a crn
+type VpcSpecificIPsecPolicy struct{}
+
+func (vpc VpcSpecificIPsecPolicy) Destroy(service *vpcv1.VpcV1, id string) (interface{}, error) {
+	return service.DeleteIpsecPolicy(service.NewDeleteIpsecPolicyOptions(id))
+}
+
+func (spec VpcSpecificIPsecPolicy) Get(service *vpcv1.VpcV1, id string) (string, string, bool, interface{}, error) {
+	ipsecPolicy, response, err := service.GetIpsecPolicy(service.NewGetIpsecPolicyOptions(id))
+	if err == nil {
+		return *ipsecPolicy.Name, "", true, response, nil
+	} else {
+		if response != nil && response.StatusCode == 404 {
+			return "", "", false, response, nil
+		} else {
+			return "", "", false, response, err
+		}
+	}
+}
+
+// listIPsecPolicies appends onto list the ipsec policies
+func listIPsecPolicies(list *set.Set, client *vpcv1.VpcV1, wg *sync.WaitGroup) {
+	defer wg.Done()
+	region := regionFromUrl(client.Service.Options.URL)
+	options := client.NewListIpsecPoliciesOptions()
+	for {
+		ipsecPolicies, _, err := client.ListIpsecPolicies(options)
+		if err != nil {
+			list.Add(&resourceInstanceWrapperErr{nil, err})
+			return
+		}
+		for _, it := range ipsecPolicies.IpsecPolicies {
+			crn := NewFakeCrn("is", "", "ipsecpolicy", *it.ID, region)
+			resourceGroupID := ""
+			if it.ResourceGroup != nil && it.ResourceGroup.ID != nil {
+				resourceGroupID = *it.ResourceGroup.ID
+			}
+			list.Add(&resourceInstanceWrapperErr{NewResourceInstanceWrapper(crn, &resourceGroupID, it.Name), nil})
+		}
+		start, err := ipsecPolicies.GetNextStart()
+		if err != nil {
+			list.Add(&resourceInstanceWrapperErr{nil, err})
+			return
+		}
+		if start == nil {
+			return
+		}
+		options.SetStart(*start)
+	}
+}
+
+// --------------------------------------
+// ike and ipsec policies can not be deleted while vpn gateway connections use them
+type VpcGenericVPNPolicyOperation struct {
+	operations VpcGenericOperation
+}
+
+func (vpc *VpcGenericVPNPolicyOperation) Fetch(ri *ResourceInstanceWrapper) {
+	vpc.operations.Fetch(ri)
+}
+
+// vpnPolicyConnections returns the number of vpn gateway connections that use the ike or ipsec policy
+func vpnPolicyConnections(ri *ResourceInstanceWrapper) int {
+	switch policy := ri.resource.(type) {
+	case *vpcv1.IkePolicy:
+		return len(policy.Connections)
+	case *vpcv1.IPsecPolicy:
+		return len(policy.Connections)
+	}
+	return 0
+}
+
+func (vpc *VpcGenericVPNPolicyOperation) Destroy(ri *ResourceInstanceWrapper) {
+	if connections := vpnPolicyConnections(ri); connections > 0 {
+		MustGlobalContext().verboseLogger.Print("policy waiting for vpn connections:", connections, " ", ri.crn.Crn)
+		return
+	}
+	vpc.operations.Destroy(ri)
+}
+
+func (vpc *VpcGenericVPNPolicyOperation) FormatInstance(ri *ResourceInstanceWrapper, fast bool) string {
+	return vpc.operations.FormatInstance(ri, fast)
+}